@@ -28,22 +28,354 @@ const (
 	ValueReplacement = "..."
 )
 
+// MonitorTiming is the breakdown of a request's latency in milliseconds.
+// Phases skipped by a reused connection are zero.
+type MonitorTiming struct {
+	DNSMs       int64 `json:"dnsMs"`
+	ConnectMs   int64 `json:"connectMs"`
+	TLSMs       int64 `json:"tlsMs"`
+	FirstByteMs int64 `json:"firstByteMs"`
+}
+
 type MonitorResult struct {
 	Status    string `json:"status"`
 	Value     string `json:"value,omitempty"`
 	UpdatedAt string `json:"updatedAt"`
+	// Attempts is the number of requests made in the run.
+	Attempts int `json:"attempts,omitempty"`
+	// StatusCode is the status code of the last response. Zero if no response was received.
+	StatusCode int `json:"statusCode,omitempty"`
+	// LatencyMs is the total latency of the last request in milliseconds.
+	// +optional
+	LatencyMs int64          `json:"latencyMs"`
+	Timing    *MonitorTiming `json:"timing,omitempty"`
+	// Size is the size of the response body in bytes.
+	// +optional
+	Size int64 `json:"size"`
+	// FailedStep is the name of the failed step of a multi-step monitor.
+	FailedStep string `json:"failedStep,omitempty"`
+	// Steps holds the result of each executed step of a multi-step monitor.
+	Steps []MonitorStepResult `json:"steps,omitempty"`
+}
+
+type MonitorStepResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+type MonitorAuthType string
+
+const (
+	MonitorAuthBasic  MonitorAuthType = "basic"
+	MonitorAuthBearer MonitorAuthType = "bearer"
+	MonitorAuthAPIKey MonitorAuthType = "apikey"
+)
+
+// MonitorAuth references a Secret in the monitor's namespace holding credentials.
+// basic reads "username" and "password" keys, bearer and apikey read the "token" key.
+type MonitorAuth struct {
+	// +kubebuilder:validation:Enum=basic;bearer;apikey
+	Type       MonitorAuthType `json:"type"`
+	SecretName string          `json:"secretName"`
+	// Header carries the token for apikey authentication. Defaults to X-API-Key.
+	// +optional
+	Header string `json:"header,omitempty"`
+}
+
+// KeySelector selects a key of a Secret or ConfigMap in the monitor's namespace.
+type KeySelector struct {
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
+// MonitorTLS configures the TLS client of a monitor.
+type MonitorTLS struct {
+	// CA holds a PEM encoded CA bundle used instead of the system roots.
+	// +optional
+	CA *KeySelector `json:"ca,omitempty"`
+	// ClientCertSecret is a kubernetes.io/tls Secret holding the client certificate
+	// and key ("tls.crt", "tls.key").
+	// +optional
+	ClientCertSecret string `json:"clientCertSecret,omitempty"`
+	// ServerName overrides the server name used for SNI and verification.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RetryPolicy retries a failed fetch before the run is reported as failure.
+type RetryPolicy struct {
+	// Attempts is the total number of requests made in a run.
+	// +kubebuilder:validation:Minimum=1
+	Attempts int `json:"attempts"`
+	// Backoff is the wait in seconds between attempts.
+	// +optional
+	Backoff int `json:"backoff,omitempty"`
+}
+
+// TCPProbe connects to a TCP endpoint, optionally exchanging a banner.
+type TCPProbe struct {
+	// Address is the host:port to connect.
+	Address string `json:"address"`
+	// Send is written to the connection after connecting.
+	// +optional
+	Send string `json:"send,omitempty"`
+	// Expect must be contained in the data read from the connection.
+	// +optional
+	Expect string `json:"expect,omitempty"`
+	// Timeout in seconds for connecting and exchanging the banner.
+	// Defaults to the timeout of the monitor, or the interval if not set.
+	// +optional
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// DNSProbe resolves a record. The value is the JSON list of answers.
+type DNSProbe struct {
+	Name string `json:"name"`
+	// Type is the record type to resolve. Defaults to A.
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;TXT;SRV
+	// +optional
+	Type string `json:"type,omitempty"`
+	// Resolver is the host:port of the DNS server. Defaults to the system resolver.
+	// +optional
+	Resolver string `json:"resolver,omitempty"`
+	// Expected answers must all be resolved for the probe to succeed.
+	// +optional
+	Expected []string `json:"expected,omitempty"`
+}
+
+// CertificateProbe performs a TLS handshake and reports the leaf certificate.
+// The chain is verified against tls.ca if set, or the system roots.
+type CertificateProbe struct {
+	// Address is the host:port to connect.
+	Address string `json:"address"`
+	// ServerName is sent for SNI and verified. Defaults to the host of Address.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// ObjectProbe reads a Kubernetes object by name, or the objects matching
+// a label selector. The value is the object's JSON, or {"items": [...]} for a selector.
+type ObjectProbe struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace of the object. Must be the monitor's namespace if set.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// EventProbe selects Events in the monitor's namespace. Every new or
+// recurring matching event is recorded as a result whose value is the Event's JSON.
+type EventProbe struct {
+	// InvolvedObjectKind matches the kind of the involved object (ex: Pod).
+	// +optional
+	InvolvedObjectKind string `json:"involvedObjectKind,omitempty"`
+	// Reasons matches any of the reasons (ex: FailedScheduling, BackOff).
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+	// +kubebuilder:validation:Enum=Normal;Warning
+	// +optional
+	Type string `json:"type,omitempty"`
+}
+
+// LogProbe counts the lines matching Pattern that the selected pods in the
+// monitor's namespace logged during the last interval. The value is
+// {"matches": N, "samples": [...]}.
+type LogProbe struct {
+	Selector metav1.LabelSelector `json:"selector"`
+	// Container to read logs from. Required for pods with multiple containers.
+	// +optional
+	Container string `json:"container,omitempty"`
+	// Pattern is a regular expression matched against each line.
+	Pattern string `json:"pattern"`
+	// Samples is the maximum number of matched lines kept in the value. Defaults to 5.
+	// +optional
+	Samples int `json:"samples,omitempty"`
+}
+
+// PrometheusProbe executes an instant PromQL query against a Prometheus
+// compatible HTTP API. Headers, auth and tls of the monitor apply to the request.
+// The value is the list of series as [{"labels": {...}, "value": 1.0}].
+type PrometheusProbe struct {
+	// URL is the base URL of the API (ex: http://prometheus:9090).
+	URL   string `json:"url"`
+	Query string `json:"query"`
+}
+
+// GRPCProbe calls grpc.health.v1.Health/Check. The value is
+// {"status": "SERVING"} and the probe fails unless the service is serving.
+type GRPCProbe struct {
+	// Address is the host:port of the server.
+	Address string `json:"address"`
+	// Service name to check. The server's overall health if empty.
+	// +optional
+	Service string `json:"service,omitempty"`
+	// UseTLS connects with TLS configured by the monitor's tls settings.
+	// +optional
+	UseTLS bool `json:"useTLS,omitempty"`
+}
+
+// ServiceReference resolves the URL of an HTTP monitor through a Service.
+type ServiceReference struct {
+	Name string `json:"name"`
+	// Namespace of the Service. Defaults to the monitor's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Port is the name or number of the Service port. Defaults to the first port.
+	// +optional
+	Port string `json:"port,omitempty"`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// +kubebuilder:validation:Enum=http;https
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+	// PerEndpoint probes every ready endpoint of the Service individually
+	// instead of the Service address, and fails if any endpoint fails.
+	// +optional
+	PerEndpoint bool `json:"perEndpoint,omitempty"`
+}
+
+// HeartbeatProbe fails when no ping arrives on the monitor's ping URL within
+// Period plus Grace seconds.
+type HeartbeatProbe struct {
+	// Period is the expected interval of pings in seconds.
+	// +kubebuilder:validation:Minimum=1
+	Period int `json:"period"`
+	// Grace is the additional delay in seconds tolerated before failing.
+	// +optional
+	Grace int `json:"grace,omitempty"`
+}
+
+// HTTPStep is a request of a multi-step monitor. Values extracted by earlier
+// steps replace ${name} in URL, Headers and Body. Headers, auth and tls of the
+// monitor apply to every step.
+type HTTPStep struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum=GET;POST;PUT;HEAD
+	// +optional
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url"`
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// +optional
+	Body string `json:"body,omitempty"`
+	// +optional
+	ExpectedStatusCodes []string `json:"expectedStatusCodes,omitempty"`
+	// Extract maps a variable name to "body:<field path>" or "header:<name>"
+	// of the response.
+	// +optional
+	Extract map[string]string `json:"extract,omitempty"`
+	// Assertions must all hold on the response body.
+	// +optional
+	Assertions []StepAssertion `json:"assertions,omitempty"`
+}
+
+// StepAssertion compares a field of the step's response body with Operand,
+// like the condition of a NotificationTrigger.
+type StepAssertion struct {
+	FieldPath string `json:"fieldPath"`
+	Op        string `json:"op"`
+	Operand   string `json:"operand"`
+}
+
+type ResponseFormat string
+
+const (
+	ResponseFormatJSON       ResponseFormat = "json"
+	ResponseFormatYAML       ResponseFormat = "yaml"
+	ResponseFormatXML        ResponseFormat = "xml"
+	ResponseFormatPrometheus ResponseFormat = "prometheus"
+	ResponseFormatText       ResponseFormat = "text"
+)
+
 // MonitorSpec defines the desired state of Monitor
 type MonitorSpec struct {
-	URL      string `json:"url"`
-	Body     string `json:"body"`
+	// URL is the endpoint fetched by HTTP monitors.
+	// +optional
+	URL      string `json:"url,omitempty"`
+	Body     string `json:"body,omitempty"`
 	Interval int    `json:"interval"`
+	// Method is the HTTP method used to fetch the resource. Defaults to GET.
+	// +kubebuilder:validation:Enum=GET;POST;PUT;HEAD
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Headers are added to the request. Content-Type defaults to application/json.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// ExpectedStatusCodes lists the status codes treated as success, either a single
+	// code ("204") or an inclusive range ("200-299"). Defaults to any code below 300.
+	// +optional
+	ExpectedStatusCodes []string `json:"expectedStatusCodes,omitempty"`
+	// Auth is resolved on every run so rotated credentials are picked up.
+	// +optional
+	Auth *MonitorAuth `json:"auth,omitempty"`
+	// +optional
+	TLS *MonitorTLS `json:"tls,omitempty"`
+	// Timeout in seconds for each request. No timeout if not set.
+	// +optional
+	Timeout int `json:"timeout,omitempty"`
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// ResponseFormat is how the response body is parsed into the document
+	// addressed by FieldPath of the triggers. Defaults to json.
+	// +kubebuilder:validation:Enum=json;yaml;xml;prometheus;text
+	// +optional
+	ResponseFormat ResponseFormat `json:"responseFormat,omitempty"`
+	// ResponsePattern is a regular expression whose named capture groups
+	// become the fields of a text response.
+	// +optional
+	ResponsePattern string `json:"responsePattern,omitempty"`
+
+	// TCP probes a TCP endpoint instead of fetching URL.
+	// +optional
+	TCP *TCPProbe `json:"tcp,omitempty"`
+	// DNS resolves a record instead of fetching URL.
+	// +optional
+	DNS *DNSProbe `json:"dns,omitempty"`
+	// Certificate inspects the TLS certificate of an endpoint instead of fetching URL.
+	// +optional
+	Certificate *CertificateProbe `json:"certificate,omitempty"`
+	// Object reads a Kubernetes object instead of fetching URL.
+	// +optional
+	Object *ObjectProbe `json:"object,omitempty"`
+	// Event watches core/v1 Events in the monitor's namespace instead of fetching URL.
+	// +optional
+	Event *EventProbe `json:"event,omitempty"`
+	// Log counts log lines of pods matching a pattern instead of fetching URL.
+	// +optional
+	Log *LogProbe `json:"log,omitempty"`
+	// Prometheus executes an instant query instead of fetching URL.
+	// +optional
+	Prometheus *PrometheusProbe `json:"prometheus,omitempty"`
+	// GRPC calls the gRPC health checking protocol instead of fetching URL.
+	// +optional
+	GRPC *GRPCProbe `json:"grpc,omitempty"`
+	// ServiceRef resolves the URL through a Service instead of using URL.
+	// +optional
+	ServiceRef *ServiceReference `json:"serviceRef,omitempty"`
+	// Heartbeat waits for pings on status.pingURL instead of fetching URL.
+	// +optional
+	Heartbeat *HeartbeatProbe `json:"heartbeat,omitempty"`
+	// Steps runs an ordered list of HTTP requests instead of fetching URL.
+	// +optional
+	Steps []HTTPStep `json:"steps,omitempty"`
 }
 
 // MonitorStatus defines the observed state of Monitor
 type MonitorStatus struct {
 	History []MonitorResult `json:"history,omitempty"`
+	// PingURL receives the pings of a heartbeat monitor.
+	PingURL string `json:"pingURL,omitempty"`
 }
 
 // +kubebuilder:object:root=true
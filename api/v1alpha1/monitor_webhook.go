@@ -0,0 +1,122 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var monitorlog = logf.Log.WithName("monitor-resource")
+
+func (r *Monitor) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-alarm-tmax-io-v1alpha1-monitor,mutating=false,failurePolicy=fail,groups=alarm.tmax.io,resources=monitors,versions=v1alpha1,name=vmonitor.kb.io
+
+var _ webhook.Validator = &Monitor{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Monitor) ValidateCreate() error {
+	monitorlog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Monitor) ValidateUpdate(old runtime.Object) error {
+	monitorlog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Monitor) ValidateDelete() error {
+	return nil
+}
+
+// probeKinds returns the fields of the probe kinds set in the spec. A plain
+// url counts as a kind of its own.
+func (s *MonitorSpec) probeKinds() []string {
+	var kinds []string
+	for _, kind := range []struct {
+		name string
+		set  bool
+	}{
+		{"url", s.URL != ""},
+		{"tcp", s.TCP != nil},
+		{"dns", s.DNS != nil},
+		{"certificate", s.Certificate != nil},
+		{"object", s.Object != nil},
+		{"event", s.Event != nil},
+		{"log", s.Log != nil},
+		{"prometheus", s.Prometheus != nil},
+		{"grpc", s.GRPC != nil},
+		{"serviceRef", s.ServiceRef != nil},
+		{"heartbeat", s.Heartbeat != nil},
+		{"steps", len(s.Steps) > 0},
+	} {
+		if kind.set {
+			kinds = append(kinds, kind.name)
+		}
+	}
+	return kinds
+}
+
+func (r *Monitor) validate() error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	if r.Spec.Interval <= 0 {
+		errs = append(errs, field.Invalid(spec.Child("interval"), r.Spec.Interval, "must be greater than 0"))
+	}
+
+	switch kinds := r.Spec.probeKinds(); len(kinds) {
+	case 0:
+		errs = append(errs, field.Required(spec, "url or one probe kind is required"))
+	case 1:
+	default:
+		errs = append(errs, field.Forbidden(spec, "only one of url or probe kinds can be set, got "+strings.Join(kinds, ", ")))
+	}
+
+	if obj := r.Spec.Object; obj != nil {
+		path := spec.Child("object")
+		if gv, err := schema.ParseGroupVersion(obj.APIVersion); err != nil {
+			errs = append(errs, field.Invalid(path.Child("apiVersion"), obj.APIVersion, err.Error()))
+		} else if gv.Group == "" && obj.Kind == "Secret" {
+			errs = append(errs, field.Forbidden(path.Child("kind"), "reading Secret is not allowed"))
+		}
+		if obj.Namespace != "" && obj.Namespace != r.Namespace {
+			errs = append(errs, field.Invalid(path.Child("namespace"), obj.Namespace, "must be the monitor's namespace"))
+		}
+		if obj.Name == "" && obj.Selector == nil {
+			errs = append(errs, field.Required(path, "one of name or selector is required"))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Monitor").GroupKind(), r.Name, errs)
+}
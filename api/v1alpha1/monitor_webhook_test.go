@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMonitorValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  MonitorSpec
+		valid bool
+	}{
+		{"url", MonitorSpec{Interval: 10, URL: "http://example.com"}, true},
+		{"tcp", MonitorSpec{Interval: 10, TCP: &TCPProbe{Address: "redis:6379"}}, true},
+		{"steps", MonitorSpec{Interval: 10, Steps: []HTTPStep{{Name: "login", URL: "http://example.com"}}}, true},
+		{"no kind", MonitorSpec{Interval: 10}, false},
+		{"no interval", MonitorSpec{URL: "http://example.com"}, false},
+		{"tcp and dns", MonitorSpec{Interval: 10, TCP: &TCPProbe{Address: "redis:6379"}, DNS: &DNSProbe{Name: "example.com"}}, false},
+		{"url and grpc", MonitorSpec{Interval: 10, URL: "http://example.com", GRPC: &GRPCProbe{Address: "app:9090"}}, false},
+		{"object", MonitorSpec{Interval: 10, Object: &ObjectProbe{APIVersion: "apps/v1", Kind: "Deployment", Name: "app"}}, true},
+		{"object in own namespace", MonitorSpec{Interval: 10, Object: &ObjectProbe{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "app"}}, true},
+		{"object in other namespace", MonitorSpec{Interval: 10, Object: &ObjectProbe{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "app"}}, false},
+		{"secret", MonitorSpec{Interval: 10, Object: &ObjectProbe{APIVersion: "v1", Kind: "Secret", Name: "token"}}, false},
+		{"object without name or selector", MonitorSpec{Interval: 10, Object: &ObjectProbe{APIVersion: "apps/v1", Kind: "Deployment"}}, false},
+	}
+
+	for _, tt := range tests {
+		m := &Monitor{ObjectMeta: metav1.ObjectMeta{Name: "m", Namespace: "default"}, Spec: tt.spec}
+		if err := m.validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: validate() = %v, want valid %t", tt.name, err, tt.valid)
+		}
+	}
+}
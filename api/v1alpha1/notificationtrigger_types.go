@@ -23,19 +23,106 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// SampleLimit is the maximum number of samples kept for an aggregation window.
+const SampleLimit = 500
+
 type NotificationTriggerResult struct {
-	Triggered bool   `json:"triggered"`
-	Message   string `json:"message,omitempty"`
-	UpdatedAt string `json:"updatedAt,omitempty"`
+	Triggered bool         `json:"triggered"`
+	State     TriggerState `json:"state,omitempty"`
+	Message   string       `json:"message,omitempty"`
+	UpdatedAt string       `json:"updatedAt,omitempty"`
+}
+
+// TriggerSample is a value of the field selected by a trigger.
+type TriggerSample struct {
+	// Value is the JSON encoded value.
+	Value string `json:"value"`
+	Time  string `json:"time"`
+}
+
+type TriggerState string
+
+const (
+	// TriggerStateInactive is the state while the condition doesn't match.
+	TriggerStateInactive TriggerState = "Inactive"
+	// TriggerStatePending is the state while the condition matches but hasn't held long enough.
+	TriggerStatePending TriggerState = "Pending"
+	// TriggerStateFiring is the state while notifications are sent.
+	TriggerStateFiring TriggerState = "Firing"
+	// TriggerStateResolved is the state after a firing condition stopped matching.
+	TriggerStateResolved TriggerState = "Resolved"
+)
+
+type FieldSource string
+
+const (
+	// FieldSourceBody selects fields of the fetched resource.
+	FieldSourceBody FieldSource = "body"
+	// FieldSourceResult selects fields of the MonitorResult such as latencyMs or statusCode.
+	FieldSourceResult FieldSource = "result"
+)
+
+// TriggerAggregation aggregates the field over a window of the latest
+// samples, and the result is compared by Op and Operand.
+type TriggerAggregation struct {
+	// +kubebuilder:validation:Enum=avg;min;max;sum;count;percentile
+	Function string `json:"function"`
+	// Percentile is the percentile for the percentile function, ex: 95
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percentile int `json:"percentile,omitempty"`
+	// Samples is the number of the latest samples in the window.
+	// +optional
+	Samples int `json:"samples,omitempty"`
+	// Duration is the window in seconds.
+	// +optional
+	Duration int `json:"duration,omitempty"`
 }
 
 // NotificationTriggerSpec defines the desired state of NotificationTrigger
 type NotificationTriggerSpec struct {
 	Notification string `json:"notification"`
 	Monitor      string `json:"monitor"`
-	FieldPath    string `json:"fieldPath"`
-	Op           string `json:"op"`
-	Operand      string `json:"operand"`
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+	// +optional
+	Op string `json:"op,omitempty"`
+	// +optional
+	Operand string `json:"operand,omitempty"`
+	// Source is the document FieldPath is applied to. Defaults to body.
+	// +kubebuilder:validation:Enum=body;result
+	// +optional
+	Source FieldSource `json:"source,omitempty"`
+	// FieldPathLanguage is the language of FieldPath. Defaults to gabs.
+	// +kubebuilder:validation:Enum=gabs;jsonpath;jmespath
+	// +optional
+	FieldPathLanguage string `json:"fieldPathLanguage,omitempty"`
+	// Condition is a CEL expression used instead of FieldPath, Op and Operand.
+	// It can refer to body, result and previous.
+	// +optional
+	Condition string `json:"condition,omitempty"`
+	// Consecutive is the number of consecutive samples the condition must
+	// match before the trigger fires. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Consecutive int `json:"consecutive,omitempty"`
+	// For is the duration in seconds the condition must keep matching before
+	// the trigger fires.
+	// +optional
+	For int `json:"for,omitempty"`
+	// RepeatInterval is the minimum seconds between notifications while the
+	// trigger keeps firing. Every matching sample is notified if not set.
+	// +optional
+	RepeatInterval int `json:"repeatInterval,omitempty"`
+	// Cooldown is the minimum seconds after a notification before another one
+	// is sent, even for a new firing after resolved.
+	// +optional
+	Cooldown int `json:"cooldown,omitempty"`
+	// Aggregation compares the aggregate of the field over a window instead
+	// of the latest value.
+	// +optional
+	Aggregation *TriggerAggregation `json:"aggregation,omitempty"`
 }
 
 // NotificationTriggerStatus defines the observed state of NotificationTrigger
@@ -43,11 +130,32 @@ type NotificationTriggerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	History []NotificationTriggerResult `json:"history,omitempty"`
+	// ConditionError is the compile error of the condition.
+	ConditionError string `json:"conditionError,omitempty"`
+	// Pending is the number of consecutive samples matching the condition.
+	Pending int `json:"pending,omitempty"`
+	// PendingSince is the datetime of the first of the consecutive matches.
+	PendingSince string `json:"pendingSince,omitempty"`
+	// State is Inactive if not set.
+	State TriggerState `json:"state,omitempty"`
+	// LastTransitionTime is the datetime State changed.
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+	// FiringSince is the datetime the trigger started firing.
+	FiringSince string `json:"firingSince,omitempty"`
+	// ResolvedAt is the datetime the trigger was resolved.
+	ResolvedAt string `json:"resolvedAt,omitempty"`
+	// LastSent is the datetime the last firing notification was sent.
+	LastSent string `json:"lastSent,omitempty"`
+	// LastSample is the previous value compared by the delta operators.
+	LastSample *TriggerSample `json:"lastSample,omitempty"`
+	// Samples is the window of the aggregation.
+	Samples []TriggerSample `json:"samples,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:shortName=ntr
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
 
 // NotificationTrigger is the Schema for the notificationtriggers API
 type NotificationTrigger struct {
@@ -0,0 +1,98 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/tmax-cloud/alarm-operator/pkg/evaluator"
+	"github.com/tmax-cloud/alarm-operator/pkg/fieldpath"
+)
+
+// log is for logging in this package.
+var notificationtriggerlog = logf.Log.WithName("notificationtrigger-resource")
+
+func (r *NotificationTrigger) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-alarm-tmax-io-v1alpha1-notificationtrigger,mutating=false,failurePolicy=fail,groups=alarm.tmax.io,resources=notificationtriggers,versions=v1alpha1,name=vnotificationtrigger.kb.io
+
+var _ webhook.Validator = &NotificationTrigger{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *NotificationTrigger) ValidateCreate() error {
+	notificationtriggerlog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *NotificationTrigger) ValidateUpdate(old runtime.Object) error {
+	notificationtriggerlog.Info("validate update", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *NotificationTrigger) ValidateDelete() error {
+	return nil
+}
+
+func (r *NotificationTrigger) validate() error {
+	var errs field.ErrorList
+	if r.Spec.Condition != "" {
+		if _, err := evaluator.CompileCondition(r.Spec.Condition); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "condition"), r.Spec.Condition, err.Error()))
+		}
+	} else {
+		if r.Spec.FieldPath == "" {
+			errs = append(errs, field.Required(field.NewPath("spec", "fieldPath"), "fieldPath or condition is required"))
+		} else if _, err := fieldpath.Compile(r.Spec.FieldPathLanguage, r.Spec.FieldPath); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "fieldPath"), r.Spec.FieldPath, err.Error()))
+		}
+		if err := evaluator.Validate(r.Spec.Op, r.Spec.Operand); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "op"), r.Spec.Op, err.Error()))
+		}
+	}
+	if agg := r.Spec.Aggregation; agg != nil {
+		path := field.NewPath("spec", "aggregation")
+		if agg.Samples <= 0 && agg.Duration <= 0 {
+			errs = append(errs, field.Required(path, "samples or duration is required"))
+		}
+		if agg.Samples > SampleLimit {
+			errs = append(errs, field.Invalid(path.Child("samples"), agg.Samples, fmt.Sprintf("must be no more than %d", SampleLimit)))
+		}
+		if agg.Function == "percentile" && agg.Percentile == 0 {
+			errs = append(errs, field.Required(path.Child("percentile"), "percentile is required for percentile function"))
+		}
+		if r.Spec.Condition != "" || evaluator.IsDelta(r.Spec.Op) {
+			errs = append(errs, field.Forbidden(path, "aggregation can't be used with condition or delta operators"))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("NotificationTrigger").GroupKind(), r.Name, errs)
+}
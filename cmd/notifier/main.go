@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/tmax-cloud/alarm-operator/pkg/heartbeat"
 	"github.com/tmax-cloud/alarm-operator/pkg/notification"
 	"github.com/tmax-cloud/alarm-operator/pkg/notification/datasource"
 	"github.com/tmax-cloud/alarm-operator/pkg/notifier/background"
@@ -43,6 +44,7 @@ func main() {
 	}
 	r := notification.NewNotificationRegistry(ds)
 	q := notification.NewNotificationQueue(ds)
+	hb := heartbeat.NewRegistry(ds)
 
 	go func() {
 		for {
@@ -59,6 +61,8 @@ func main() {
 
 	router := mux.NewRouter()
 	router.Handle("/internal/notification/{id}", handler.NewRegistryHandler(ctx, r, logger)).Methods("POST")
+	router.Handle("/internal/heartbeat/{id}", handler.NewHeartbeatRegistryHandler(ctx, hb, logger)).Methods("GET", "POST", "DELETE")
+	router.Handle("/heartbeat/{id}/{token}", handler.NewPingHandler(ctx, hb, logger)).Methods("GET", "POST")
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("I'm fine"))
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+const defaultAPIKeyHeader = "X-API-Key"
+
+// authHeaders reads the credentials referenced by the monitor's auth section and
+// returns the request headers carrying them.
+func (r *MonitorReconciler) authHeaders(ctx context.Context, o *tmaxiov1alpha1.Monitor) (map[string]string, error) {
+	auth := o.Spec.Auth
+	if auth == nil {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: auth.SecretName}, secret); err != nil {
+		return nil, err
+	}
+
+	switch auth.Type {
+	case tmaxiov1alpha1.MonitorAuthBasic:
+		cred := string(secret.Data["username"]) + ":" + string(secret.Data["password"])
+		return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(cred))}, nil
+	case tmaxiov1alpha1.MonitorAuthBearer:
+		return map[string]string{"Authorization": "Bearer " + string(secret.Data["token"])}, nil
+	case tmaxiov1alpha1.MonitorAuthAPIKey:
+		header := auth.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		return map[string]string{header: string(secret.Data["token"])}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported auth type: %s", auth.Type)
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type certificateProbeValue struct {
+	NotAfter      string   `json:"notAfter"`
+	DaysRemaining int      `json:"daysRemaining"`
+	Issuer        string   `json:"issuer"`
+	Subject       string   `json:"subject"`
+	DNSNames      []string `json:"dnsNames"`
+	ChainValid    bool     `json:"chainValid"`
+	VerifyError   string   `json:"verifyError,omitempty"`
+}
+
+// probeCertificate performs a TLS handshake and reports the leaf certificate.
+// Only a failed handshake fails the probe; expiry and chain validity are left
+// to the triggers.
+func (r *MonitorReconciler) probeCertificate(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec.Certificate
+	serverName := spec.ServerName
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(spec.Address)
+		if err != nil {
+			return nil, err
+		}
+		serverName = host
+	}
+
+	var roots *x509.CertPool
+	if o.Spec.TLS != nil && o.Spec.TLS.CA != nil {
+		ca, _, err := r.readKey(ctx, o.Namespace, *o.Spec.TLS.CA)
+		if err != nil {
+			return nil, err
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificate in CA bundle")
+		}
+	}
+
+	// a server that accepts but never completes the handshake would block forever
+	ctx, cancel := withDefaultTimeout(ctx, o)
+	defer cancel()
+
+	start := time.Now()
+	d := &tls.Dialer{Config: &tls.Config{ServerName: serverName, InsecureSkipVerify: true}}
+	conn, err := d.DialContext(ctx, "tcp", spec.Address)
+	result.LatencyMs = msSince(start)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate presented by %s", spec.Address)
+	}
+	leaf := certs[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+
+	value := certificateProbeValue{
+		NotAfter:      leaf.NotAfter.Format(time.RFC3339),
+		DaysRemaining: int(time.Until(leaf.NotAfter).Hours() / 24),
+		Issuer:        leaf.Issuer.String(),
+		Subject:       leaf.Subject.String(),
+		DNSNames:      leaf.DNSNames,
+		ChainValid:    true,
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: serverName, Roots: roots, Intermediates: intermediates})
+	if err != nil {
+		value.ChainValid = false
+		value.VerifyError = err.Error()
+	}
+
+	dat, err := json.Marshal(value)
+	result.Size = int64(len(dat))
+	return dat, err
+}
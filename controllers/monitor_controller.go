@@ -19,19 +19,21 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptrace"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/Jeffail/gabs/v2"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -51,12 +53,29 @@ func init() {
 // MonitorReconciler reconciles a Monitor object
 type MonitorReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Clientset kubernetes.Interface
+	// APIReader reads objects from the API server without the manager's cache.
+	APIReader client.Reader
+	Log       logr.Logger
+	Scheme    *runtime.Scheme
 }
 
 // +kubebuilder:rbac:groups=alarm.tmax.io,resources=monitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=alarm.tmax.io,resources=monitors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=get;list
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=pods/log,verbs=get;
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch;
+// Kinds read by object monitors. Other kinds are granted to the service account through
+// a RoleBinding in the namespace or config/rbac/object_reader_role.yaml.
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets;replicasets,verbs=get;list
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list
 
 func (r *MonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	const finalizer = "monitor.finalizer.alarm-operator.tmax.io"
@@ -74,8 +93,17 @@ func (r *MonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	if !o.ObjectMeta.DeletionTimestamp.IsZero() {
 		if hasFinalizer(o.ObjectMeta, finalizer) {
+			// by id, so a registration whose ping URL never made it to the status is removed too
+			if o.Spec.Heartbeat != nil || o.Status.PingURL != "" {
+				if err := notifier.UnregisterHeartbeat(extractId(o.Name, o.Namespace)); err != nil {
+					logger.Error(err, "Failed to unregister heartbeat")
+					return ctrl.Result{RequeueAfter: requeueDuration}, err
+				}
+			}
 			removeFinalizer(&o.ObjectMeta, finalizer)
 			s.Schedule(o.Name).Cancel()
+			clients.remove(req.NamespacedName.String())
+			seenEvents.remove(req.NamespacedName.String())
 			if err := r.Update(ctx, o); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -90,223 +118,270 @@ func (r *MonitorReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}
 
+	if o.Spec.Heartbeat != nil {
+		if err := r.registerHeartbeat(ctx, o); err != nil {
+			logger.Error(err, "Failed to register heartbeat")
+			return ctrl.Result{RequeueAfter: requeueDuration}, err
+		}
+	}
+
 	s.Schedule(o.Name).Every(o.Spec.Interval).Second().Do(func(ctx context.Context) error {
-		retCode, dat, err := fetchResource(ctx, o.Spec.URL, []byte(o.Spec.Body))
-		result := tmaxiov1alpha1.MonitorResult{
-			Status:    "Success",
-			Value:     string(dat),
-			UpdatedAt: time.Now().Format(time.RFC3339),
+		if o.Spec.Event != nil {
+			events, err := r.probeEvents(ctx, o)
+			if err != nil {
+				logger.Error(err, "failed to list events")
+				return r.record(ctx, o, tmaxiov1alpha1.MonitorResult{Status: "Fail"}, nil)
+			}
+			for _, e := range events {
+				if err := r.record(ctx, o, e.result, e.dat); err != nil {
+					return err
+				}
+			}
+			return nil
 		}
-		if err != nil || retCode >= 300 {
+
+		result, dat, err := r.probeWithRetry(ctx, o)
+		result.Status = "Success"
+		if err != nil {
 			result.Status = "Fail"
 		}
+		return r.record(ctx, o, result, dat)
+	})
 
-		latestIdx := len(o.Status.History) - 1
-		if len(o.Status.History) > 0 && len(o.Status.History[latestIdx].Value) > tmaxiov1alpha1.ValueSizeLimit {
-			o.Status.History[latestIdx].Value = tmaxiov1alpha1.ValueReplacement
-		}
-		o.Status.History = append(o.Status.History, result)
-		if len(o.Status.History) > tmaxiov1alpha1.HistoryLimit {
-			start := len(o.Status.History) - tmaxiov1alpha1.HistoryLimit
-			o.Status.History = o.Status.History[start:]
-		}
+	return ctrl.Result{}, nil
+}
 
-		logger.Info("Update", "value", result.Status)
-		if err = r.Status().Update(ctx, o); err != nil {
+// record appends the result to the monitor's history and hands it to the
+// subscribing notification triggers.
+func (r *MonitorReconciler) record(ctx context.Context, o *tmaxiov1alpha1.Monitor, result tmaxiov1alpha1.MonitorResult, dat []byte) error {
+	logger := r.Log.WithValues("monitor", types.NamespacedName{Namespace: o.Namespace, Name: o.Name})
+
+	result.Value = string(dat)
+	result.UpdatedAt = time.Now().Format(time.RFC3339)
+
+	var prevValue string
+	latestIdx := len(o.Status.History) - 1
+	if latestIdx >= 0 {
+		prevValue = o.Status.History[latestIdx].Value
+	}
+	if len(o.Status.History) > 0 && len(o.Status.History[latestIdx].Value) > tmaxiov1alpha1.ValueSizeLimit {
+		o.Status.History[latestIdx].Value = tmaxiov1alpha1.ValueReplacement
+	}
+	o.Status.History = append(o.Status.History, result)
+	if len(o.Status.History) > tmaxiov1alpha1.HistoryLimit {
+		start := len(o.Status.History) - tmaxiov1alpha1.HistoryLimit
+		o.Status.History = o.Status.History[start:]
+	}
+
+	logger.Info("Update", "value", result.Status)
+	if err := r.Status().Update(ctx, o); err != nil {
+		return err
+	}
+
+	subscribers := parseSubscribers(o)
+	if len(subscribers) == 0 {
+		return nil
+	}
+	// Triggers on the result still run when the body can't be parsed.
+	doc, err := parseResponse(o.Spec, dat)
+	if err != nil {
+		logger.Error(err, "failed to parse response", "format", o.Spec.ResponseFormat)
+	}
+	var prev []byte
+	if prevValue != "" {
+		prev, _ = parseResponse(o.Spec, []byte(prevValue))
+	}
+	for _, s := range subscribers {
+		if err := r.trigger(ctx, s, result, doc, prev); err != nil {
+			logger.Error(err, "failed to handle notification trigger", "trigger", s)
 			return err
 		}
+	}
 
-		// FIXME: next trigger handling logic must be seperated.
-		subscribers := parseSubscribers(o)
-		for _, s := range subscribers {
-			nt := &tmaxiov1alpha1.NotificationTrigger{}
-			if err := r.Get(ctx, s, nt); err != nil {
-				logger.Error(err, "failed to get notification trigger")
-				return err
-			}
+	return nil
+}
 
-			jsonParsed, err := gabs.ParseJSON(dat)
-			if err != nil {
-				return err
-			}
-			v := jsonParsed.Path(nt.Spec.FieldPath).Data()
-			logger.Info("parsed field", "value", v)
-
-			result := tmaxiov1alpha1.NotificationTriggerResult{}
-			if eval(v, nt.Spec.Operand, nt.Spec.Op) {
-				n := &tmaxiov1alpha1.Notification{}
-				if err := r.Get(ctx, types.NamespacedName{Namespace: nt.Namespace, Name: nt.Spec.Notification}, n); err != nil {
-					result.Message = "failed to get notification from resource"
-					logger.Error(err, result.Message)
-				}
-				if err = sendNotification(*n); err != nil {
-					result.Message = "failed to send notification"
-					logger.Error(err, result.Message)
-				}
-				result.Triggered = true
-				result.UpdatedAt = time.Now().Format(time.RFC3339)
-			} else {
-				result.Triggered = false
-				result.Message = fmt.Sprintf("condition not matched")
-			}
+// probeWithRetry probes the target until it succeeds or the retry policy
+// of the monitor is exhausted. The returned result holds the response metadata
+// of the last attempt and the number of attempts made.
+func (r *MonitorReconciler) probeWithRetry(ctx context.Context, o *tmaxiov1alpha1.Monitor) (tmaxiov1alpha1.MonitorResult, []byte, error) {
+	maxAttempts, backoff := 1, time.Duration(0)
+	if o.Spec.Retry != nil {
+		if o.Spec.Retry.Attempts > 1 {
+			maxAttempts = o.Spec.Retry.Attempts
+		}
+		backoff = time.Duration(o.Spec.Retry.Backoff) * time.Second
+	}
 
-			nt.Status.History = append(nt.Status.History, result)
-			if len(nt.Status.History) > tmaxiov1alpha1.HistoryLimit {
-				start := len(nt.Status.History) - tmaxiov1alpha1.HistoryLimit
-				nt.Status.History = nt.Status.History[start:]
-			}
+	for attempt := 1; ; attempt++ {
+		reqCtx, cancel := ctx, context.CancelFunc(func() {})
+		if o.Spec.Timeout > 0 {
+			reqCtx, cancel = context.WithTimeout(ctx, time.Duration(o.Spec.Timeout)*time.Second)
+		}
+		result := tmaxiov1alpha1.MonitorResult{Attempts: attempt}
+		dat, err := r.probe(reqCtx, o, &result)
+		cancel()
 
-			if err := r.Status().Update(ctx, nt); err != nil {
-				return err
-			}
+		if err == nil || attempt >= maxAttempts {
+			return result, dat, err
 		}
 
-		return nil
-	})
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result, dat, ctx.Err()
+		}
+	}
+}
 
-	return ctrl.Result{}, nil
+// withDefaultTimeout bounds ctx by the interval of the monitor unless it
+// already has a deadline, for probes that would otherwise block forever.
+func withDefaultTimeout(ctx context.Context, o *tmaxiov1alpha1.Monitor) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Duration(o.Spec.Interval)*time.Second)
+}
+
+// probe runs a single probe of the monitor's kind. A non-nil error marks the
+// run as failed; the returned data is still handed to the triggers.
+func (r *MonitorReconciler) probe(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	switch {
+	case o.Spec.TCP != nil:
+		return probeTCP(ctx, o, result)
+	case o.Spec.DNS != nil:
+		return probeDNS(ctx, *o.Spec.DNS, result)
+	case o.Spec.Certificate != nil:
+		return r.probeCertificate(ctx, o, result)
+	case o.Spec.Object != nil:
+		return r.probeObject(ctx, o, result)
+	case o.Spec.Log != nil:
+		return r.probeLog(ctx, o, result)
+	case o.Spec.Prometheus != nil:
+		return r.probePrometheus(ctx, o, result)
+	case o.Spec.GRPC != nil:
+		return r.probeGRPC(ctx, o, result)
+	case o.Spec.ServiceRef != nil:
+		return r.probeService(ctx, o, result)
+	case o.Spec.Heartbeat != nil:
+		return probeHeartbeat(o, result)
+	case len(o.Spec.Steps) > 0:
+		return r.probeSteps(ctx, o, result)
+	default:
+		return r.fetch(ctx, o, o.Spec.URL, result)
+	}
+}
+
+// fetch requests url with the HTTP settings of the monitor.
+func (r *MonitorReconciler) fetch(ctx context.Context, o *tmaxiov1alpha1.Monitor, url string, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	cli, err := r.httpClient(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := r.authHeaders(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	spec := o.Spec
+	spec.URL = url
+	dat, _, err := fetchResource(ctx, cli, spec, headers, result)
+	if err == nil && !isExpectedStatus(result.StatusCode, o.Spec.ExpectedStatusCodes) {
+		err = fmt.Errorf("unexpected status code: %d", result.StatusCode)
+	}
+	return dat, err
 }
 
-func fetchResource(ctx context.Context, url string, body []byte) (int, []byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, bytes.NewBuffer(body))
+// fetchResource requests the resource and records the status code, latency
+// breakdown and size of the response in result. The response headers are
+// returned along with the body.
+func fetchResource(ctx context.Context, cli *http.Client, spec tmaxiov1alpha1.MonitorSpec, authHeaders map[string]string, result *tmaxiov1alpha1.MonitorResult) ([]byte, http.Header, error) {
+	var dnsStart, connectStart, tlsStart time.Time
+	timing := &tmaxiov1alpha1.MonitorTiming{}
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { timing.DNSMs = msSince(dnsStart) },
+		ConnectStart:         func(string, string) { connectStart = time.Now() },
+		ConnectDone:          func(string, string, error) { timing.ConnectMs = msSince(connectStart) },
+		TLSHandshakeStart:    func() { tlsStart = time.Now() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { timing.TLSMs = msSince(tlsStart) },
+		GotFirstResponseByte: func() { timing.FirstByteMs = msSince(start) },
+	}
+
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), method, spec.URL, bytes.NewBufferString(spec.Body))
 	if err != nil {
-		return http.StatusBadRequest, nil, err
+		return nil, nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	response, err := httpcli.Do(req)
+	for k, v := range spec.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range authHeaders {
+		req.Header.Set(k, v)
+	}
+	response, err := cli.Do(req)
 	if err != nil {
-		return http.StatusInternalServerError, nil, err
+		result.LatencyMs = msSince(start)
+		return nil, nil, err
 	}
 	defer response.Body.Close()
-	body, err = ioutil.ReadAll(response.Body)
-	return response.StatusCode, body, err
+	body, err := ioutil.ReadAll(response.Body)
+
+	result.StatusCode = response.StatusCode
+	result.LatencyMs = msSince(start)
+	result.Timing = timing
+	result.Size = int64(len(body))
+	return body, response.Header, err
 }
 
-func parseSubscribers(o *tmaxiov1alpha1.Monitor) []types.NamespacedName {
-	ret := []types.NamespacedName{}
-	subscribers := strings.Split(o.Annotations["subscribers"], ",")
-	for _, subscriber := range subscribers {
-		if subscriber == "" {
-			continue
-		}
-		tokens := strings.Split(subscriber, "/")
-		ret = append(ret, types.NamespacedName{Namespace: tokens[0], Name: tokens[1]})
-	}
-	return ret
+func msSince(t time.Time) int64 {
+	return int64(time.Since(t) / time.Millisecond)
 }
 
-func eval(op1 interface{}, op2 string, op string) bool {
-	switch op {
-	case "gt", "<":
-		switch op1 := op1.(type) {
-		case int:
-			operand, _ := strconv.Atoi(op2)
-			if op1 > operand {
-				return true
-			}
-		case float64:
-			operand, _ := strconv.Atoi(op2)
-			if op1 > float64(operand) {
-				return true
-			}
-		case string:
-			if op1 > op2 {
-				return true
-			}
-		}
-	case "gte", "<=":
-		switch op1 := op1.(type) {
-		case int:
-			operand, _ := strconv.Atoi(op2)
-			if op1 >= operand {
-				return true
-			}
-		case float64:
-			operand, _ := strconv.Atoi(op2)
-			if op1 >= float64(operand) {
-				return true
-			}
-		case string:
-			if op1 >= op2 {
-				return true
-			}
-		}
-	case "eq", "==":
-		switch op1 := op1.(type) {
-		case int:
-			operand, _ := strconv.Atoi(op2)
-			if op1 == operand {
-				return true
-			}
-		case float64:
-			operand, _ := strconv.Atoi(op2)
-			if op1 == float64(operand) {
-				return true
-			}
-		case string:
-			if op1 == op2 {
-				return true
-			}
+// isExpectedStatus reports whether code matches one of expected, which holds
+// single codes ("204") or inclusive ranges ("200-299").
+func isExpectedStatus(code int, expected []string) bool {
+	if len(expected) == 0 {
+		return code < 300
+	}
+	for _, e := range expected {
+		tokens := strings.SplitN(strings.TrimSpace(e), "-", 2)
+		low, err := strconv.Atoi(strings.TrimSpace(tokens[0]))
+		if err != nil {
+			continue
 		}
-	case "lte", ">=":
-		switch op1 := op1.(type) {
-		case int:
-			operand, _ := strconv.Atoi(op2)
-			if op1 <= operand {
-				return true
-			}
-		case float64:
-			operand, _ := strconv.Atoi(op2)
-			if op1 <= float64(operand) {
-				return true
-			}
-		case string:
-			if op1 <= op2 {
-				return true
+		high := low
+		if len(tokens) == 2 {
+			if high, err = strconv.Atoi(strings.TrimSpace(tokens[1])); err != nil {
+				continue
 			}
 		}
-	case "lt", ">":
-		switch op1 := op1.(type) {
-		case int:
-			operand, _ := strconv.Atoi(op2)
-			if op1 < operand {
-				return true
-			}
-		case float64:
-			operand, _ := strconv.Atoi(op2)
-			if op1 < float64(operand) {
-				return true
-			}
-		case string:
-			if op1 < op2 {
-				return true
-			}
+		if code >= low && code <= high {
+			return true
 		}
 	}
-
 	return false
 }
 
-func sendNotification(o tmaxiov1alpha1.Notification) error {
-	if o.Status.EndPoint == "" {
-		return fmt.Errorf("notification's endpoint not prepared")
-	}
-
-	req, err := http.NewRequest("POST", o.Status.EndPoint, bytes.NewBuffer([]byte("")))
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", o.Status.ApiKey)
-
-	if _, err = http.DefaultClient.Do(req); err != nil {
-		return err
+func parseSubscribers(o *tmaxiov1alpha1.Monitor) []types.NamespacedName {
+	ret := []types.NamespacedName{}
+	subscribers := strings.Split(o.Annotations["subscribers"], ",")
+	for _, subscriber := range subscribers {
+		if subscriber == "" {
+			continue
+		}
+		tokens := strings.Split(subscriber, "/")
+		ret = append(ret, types.NamespacedName{Namespace: tokens[0], Name: tokens[1]})
 	}
-	return nil
+	return ret
 }
 
 func (r *MonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&tmaxiov1alpha1.Monitor{}).
 		Complete(r)
-}
\ No newline at end of file
+}
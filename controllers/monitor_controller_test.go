@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestIsExpectedStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		expected []string
+		want     bool
+	}{
+		{"default success", 200, nil, true},
+		{"default redirect", 302, nil, false},
+		{"default error", 500, nil, false},
+		{"default below 200", 102, nil, true},
+		{"single code", 204, []string{"204"}, true},
+		{"single code not matched", 200, []string{"204"}, false},
+		{"range", 250, []string{"200-299"}, true},
+		{"range low bound", 200, []string{"200-299"}, true},
+		{"range high bound", 299, []string{"200-299"}, true},
+		{"out of range", 300, []string{"200-299"}, false},
+		{"any of list", 404, []string{"200-299", "404"}, true},
+		{"spaces", 301, []string{" 301 - 302 "}, true},
+		{"invalid entries ignored", 200, []string{"ok", "2xx", "200-abc", "200"}, true},
+		{"only invalid entries", 200, []string{"ok"}, false},
+		{"error code expected", 503, []string{"500-599"}, true},
+	}
+	for _, tt := range tests {
+		if got := isExpectedStatus(tt.code, tt.expected); got != tt.want {
+			t.Errorf("%s: isExpectedStatus(%d, %v) = %t, want %t", tt.name, tt.code, tt.expected, got, tt.want)
+		}
+	}
+}
+
+func TestProbeWithRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		retry        *tmaxiov1alpha1.RetryPolicy
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"success", 0, nil, 1, false},
+		{"no retry", 1, nil, 1, true},
+		{"single attempt", 1, &tmaxiov1alpha1.RetryPolicy{Attempts: 1}, 1, true},
+		{"recovers", 2, &tmaxiov1alpha1.RetryPolicy{Attempts: 3}, 3, false},
+		{"exhausted", 5, &tmaxiov1alpha1.RetryPolicy{Attempts: 3}, 3, true},
+		{"success stops retry", 0, &tmaxiov1alpha1.RetryPolicy{Attempts: 3}, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = w.Write([]byte(`{"status":"UP"}`))
+			}))
+			defer server.Close()
+
+			r := &MonitorReconciler{}
+			o := &tmaxiov1alpha1.Monitor{Spec: tmaxiov1alpha1.MonitorSpec{URL: server.URL, Interval: 10, Retry: tt.retry}}
+			result, dat, err := r.probeWithRetry(context.Background(), o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeWithRetry() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if result.Attempts != tt.wantAttempts || int(atomic.LoadInt32(&requests)) != tt.wantAttempts {
+				t.Errorf("attempts = %d, requests = %d, want %d", result.Attempts, requests, tt.wantAttempts)
+			}
+			if !tt.wantErr && (result.StatusCode != http.StatusOK || string(dat) != `{"status":"UP"}`) {
+				t.Errorf("probeWithRetry() = %d %s, want the successful response", result.StatusCode, dat)
+			}
+			if tt.wantErr && result.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("status code = %d, want the last attempt's", result.StatusCode)
+			}
+		})
+	}
+}
+
+func TestProbeWithRetryTimeoutAndCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	r := &MonitorReconciler{}
+	o := &tmaxiov1alpha1.Monitor{Spec: tmaxiov1alpha1.MonitorSpec{
+		URL:      server.URL,
+		Interval: 10,
+		Timeout:  1,
+		Retry:    &tmaxiov1alpha1.RetryPolicy{Attempts: 3, Backoff: 60},
+	}}
+
+	// each attempt times out, and canceling the run stops waiting for the backoff
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(1500*time.Millisecond, cancel)
+	start := time.Now()
+	result, _, err := r.probeWithRetry(ctx, o)
+	if err != context.Canceled {
+		t.Errorf("probeWithRetry() error = %v, want %v", err, context.Canceled)
+	}
+	if result.Attempts != 1 {
+		t.Errorf("attempts = %d, want 1", result.Attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("probeWithRetry() took %v", elapsed)
+	}
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+// probeDNS resolves the record and fails when the lookup fails or an expected
+// answer is missing. The value is the JSON list of answers.
+func probeDNS(ctx context.Context, spec tmaxiov1alpha1.DNSProbe, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	resolver := net.DefaultResolver
+	if spec.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, spec.Resolver)
+			},
+		}
+	}
+
+	start := time.Now()
+	answers, err := lookup(ctx, resolver, spec.Type, spec.Name)
+	result.LatencyMs = msSince(start)
+	if answers == nil {
+		answers = []string{}
+	}
+	dat, _ := json.Marshal(answers)
+	result.Size = int64(len(dat))
+	if err != nil {
+		return dat, err
+	}
+
+	for _, e := range spec.Expected {
+		if !containsAnswer(answers, e) {
+			return dat, fmt.Errorf("expected answer %s not resolved", e)
+		}
+	}
+	return dat, nil
+}
+
+func lookup(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	answers := []string{}
+	switch strings.ToUpper(recordType) {
+	case "", "A", "AAAA":
+		addrs, err := resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		v4 := strings.ToUpper(recordType) != "AAAA"
+		for _, addr := range addrs {
+			if (addr.IP.To4() != nil) == v4 {
+				answers = append(answers, addr.IP.String())
+			}
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "TXT":
+		return resolver.LookupTXT(ctx, name)
+	case "SRV":
+		_, srvs, err := resolver.LookupSRV(ctx, "", "", name)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range srvs {
+			answers = append(answers, net.JoinHostPort(srv.Target, strconv.Itoa(int(srv.Port))))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type: %s", recordType)
+	}
+	return answers, nil
+}
+
+// containsAnswer compares answers ignoring case and the trailing dot of names.
+func containsAnswer(answers []string, expected string) bool {
+	expected = strings.TrimSuffix(strings.ToLower(expected), ".")
+	for _, a := range answers {
+		if strings.TrimSuffix(strings.ToLower(a), ".") == expected {
+			return true
+		}
+	}
+	return false
+}
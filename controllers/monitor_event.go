@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type eventResult struct {
+	result tmaxiov1alpha1.MonitorResult
+	dat    []byte
+}
+
+// eventCache remembers the count of events already recorded per monitor.
+type eventCache struct {
+	mutex  sync.Mutex
+	counts map[string]map[types.UID]int32
+}
+
+var seenEvents = &eventCache{counts: make(map[string]map[types.UID]int32)}
+
+// seen records the event's count and reports whether it was already recorded.
+func (c *eventCache) seen(key string, e corev1.Event) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	counts, ok := c.counts[key]
+	if !ok {
+		counts = make(map[types.UID]int32)
+		c.counts[key] = counts
+	}
+	if count, ok := counts[e.UID]; ok && count >= e.Count {
+		return true
+	}
+	counts[e.UID] = e.Count
+	return false
+}
+
+// retain forgets the events of key that are not in events any more, so
+// expired events don't pile up. It reports whether key had no entry yet.
+func (c *eventCache) retain(key string, events []corev1.Event) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.counts[key]; !ok {
+		c.counts[key] = make(map[types.UID]int32)
+		return true
+	}
+	live := make(map[types.UID]bool, len(events))
+	for _, e := range events {
+		live[e.UID] = true
+	}
+	for uid := range c.counts[key] {
+		if !live[uid] {
+			delete(c.counts[key], uid)
+		}
+	}
+	return false
+}
+
+func (c *eventCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.counts, key)
+}
+
+// probeEvents returns a result for every matching event that is new or
+// recurred since it was last recorded. On the first run after the manager
+// starts, events last seen before the previous run are skipped so a restart
+// doesn't replay them. Later runs rely on the cache only.
+// Events are listed uncached so no cluster-wide Event informer is started.
+func (r *MonitorReconciler) probeEvents(ctx context.Context, o *tmaxiov1alpha1.Monitor) ([]eventResult, error) {
+	spec := o.Spec.Event
+	events := &corev1.EventList{}
+	opts := []client.ListOption{client.InNamespace(o.Namespace)}
+	if selector := eventFieldSelector(spec); !selector.Empty() {
+		opts = append(opts, client.MatchingFieldsSelector{Selector: selector})
+	}
+	if err := r.APIReader.List(ctx, events, opts...); err != nil {
+		return nil, err
+	}
+
+	key := path.Join(o.Namespace, o.Name)
+	first := seenEvents.retain(key, events.Items)
+	cutoff := o.CreationTimestamp.Time
+	if len(o.Status.History) > 0 {
+		if t, err := time.Parse(time.RFC3339, o.Status.History[len(o.Status.History)-1].UpdatedAt); err == nil {
+			cutoff = t
+		}
+	}
+
+	ret := []eventResult{}
+	for _, e := range events.Items {
+		if !matchEvent(spec, e) {
+			continue
+		}
+		// seen also marks the events skipped by the cutoff, so they aren't recorded later
+		if seenEvents.seen(key, e) || (first && eventTime(e).Before(cutoff)) {
+			continue
+		}
+		dat, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, eventResult{
+			result: tmaxiov1alpha1.MonitorResult{Status: "Success", Size: int64(len(dat))},
+			dat:    dat,
+		})
+	}
+	return ret, nil
+}
+
+// eventFieldSelector narrows the list on the API server. Several reasons can't
+// be expressed as a field selector and are left to matchEvent.
+func eventFieldSelector(spec *tmaxiov1alpha1.EventProbe) fields.Selector {
+	selectors := []fields.Selector{}
+	if spec.Type != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("type", spec.Type))
+	}
+	if spec.InvolvedObjectKind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", spec.InvolvedObjectKind))
+	}
+	if len(spec.Reasons) == 1 {
+		selectors = append(selectors, fields.OneTermEqualSelector("reason", spec.Reasons[0]))
+	}
+	return fields.AndSelectors(selectors...)
+}
+
+func matchEvent(spec *tmaxiov1alpha1.EventProbe, e corev1.Event) bool {
+	if spec.Type != "" && spec.Type != e.Type {
+		return false
+	}
+	if spec.InvolvedObjectKind != "" && spec.InvolvedObjectKind != e.InvolvedObject.Kind {
+		return false
+	}
+	if len(spec.Reasons) == 0 {
+		return true
+	}
+	for _, reason := range spec.Reasons {
+		if reason == e.Reason {
+			return true
+		}
+	}
+	return false
+}
+
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case e.Series != nil:
+		return e.Series.LastObservedTime.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestEventCacheRetain(t *testing.T) {
+	c := &eventCache{counts: make(map[string]map[types.UID]int32)}
+	if !c.retain("default/m", nil) {
+		t.Error("retain() doesn't report new key")
+	}
+	event := func(uid string, count int32) corev1.Event {
+		return corev1.Event{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}, Count: count}
+	}
+
+	if c.seen("default/m", event("a", 1)) || c.seen("default/m", event("b", 1)) {
+		t.Fatal("new events reported as seen")
+	}
+	if !c.seen("default/m", event("a", 1)) {
+		t.Error("recorded event not reported as seen")
+	}
+	if c.seen("default/m", event("a", 2)) {
+		t.Error("recurred event reported as seen")
+	}
+
+	if c.retain("default/m", []corev1.Event{event("a", 2)}) {
+		t.Error("retain() reports known key as new")
+	}
+	if _, ok := c.counts["default/m"]["b"]; ok {
+		t.Error("expired event not pruned")
+	}
+	if !c.seen("default/m", event("a", 2)) {
+		t.Error("live event pruned")
+	}
+}
+
+func TestEventFieldSelector(t *testing.T) {
+	tests := []struct {
+		spec tmaxiov1alpha1.EventProbe
+		want string
+	}{
+		{tmaxiov1alpha1.EventProbe{}, ""},
+		{tmaxiov1alpha1.EventProbe{Type: "Warning", InvolvedObjectKind: "Pod"}, "type=Warning,involvedObject.kind=Pod"},
+		{tmaxiov1alpha1.EventProbe{Reasons: []string{"BackOff"}}, "reason=BackOff"},
+		{tmaxiov1alpha1.EventProbe{Type: "Warning", InvolvedObjectKind: "Pod", Reasons: []string{"BackOff"}}, "type=Warning,involvedObject.kind=Pod,reason=BackOff"},
+		{tmaxiov1alpha1.EventProbe{Reasons: []string{"BackOff", "Failed"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := eventFieldSelector(&tt.spec).String(); got != tt.want {
+			t.Errorf("eventFieldSelector(%+v) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestProbeEventsCutoff(t *testing.T) {
+	now := time.Now()
+	event := func(name string, last time.Time) runtime.Object {
+		return &corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: name, Namespace: "default", UID: uuid.NewUUID()},
+			Type:          "Warning",
+			Count:         1,
+			LastTimestamp: metav1.NewTime(last),
+		}
+	}
+	old := event("old", now.Add(-time.Hour))
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := fake.NewFakeClientWithScheme(scheme, old, event("new", now))
+	r := &MonitorReconciler{APIReader: c}
+
+	o := &tmaxiov1alpha1.Monitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "cutoff", Namespace: "default", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))},
+		Spec:       tmaxiov1alpha1.MonitorSpec{Event: &tmaxiov1alpha1.EventProbe{Type: "Warning"}},
+		Status: tmaxiov1alpha1.MonitorStatus{History: []tmaxiov1alpha1.MonitorResult{
+			{Status: "Fail", UpdatedAt: now.Add(-time.Minute).Format(time.RFC3339)},
+		}},
+	}
+	defer seenEvents.remove("default/cutoff")
+
+	// the first run skips events older than the last result
+	results, err := r.probeEvents(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("first run recorded %d events, want 1", len(results))
+	}
+
+	// later runs don't use the cutoff, so a late event older than the last result is recorded once
+	o.Status.History = append(o.Status.History, tmaxiov1alpha1.MonitorResult{Status: "Fail", UpdatedAt: now.Add(time.Minute).Format(time.RFC3339)})
+	late := event("late", now.Add(-30*time.Second))
+	if err := c.Create(context.Background(), late); err != nil {
+		t.Fatal(err)
+	}
+	results, err = r.probeEvents(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("second run recorded %d events, want the late one", len(results))
+	}
+	if results, _ := r.probeEvents(context.Background(), o); len(results) != 0 {
+		t.Errorf("third run recorded %d events, want none", len(results))
+	}
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type grpcProbeValue struct {
+	Status string `json:"status"`
+}
+
+// probeGRPC checks the health of the service through the gRPC health
+// checking protocol. Any status but SERVING fails the probe.
+func (r *MonitorReconciler) probeGRPC(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec.GRPC
+	opts := []grpc.DialOption{grpc.WithBlock()}
+	if spec.UseTLS {
+		cfg, err := r.tlsConfig(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	// the dial blocks until connected, so bound it by the interval at least
+	ctx, cancel := withDefaultTimeout(ctx, o)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { result.LatencyMs = msSince(start) }()
+
+	value := grpcProbeValue{Status: healthpb.HealthCheckResponse_UNKNOWN.String()}
+	conn, err := grpc.DialContext(ctx, spec.Address, opts...)
+	if err != nil {
+		dat, _ := json.Marshal(value)
+		return dat, err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: spec.Service})
+	if err == nil {
+		value.Status = resp.Status.String()
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			err = fmt.Errorf("service is %s", value.Status)
+		}
+	}
+	dat, _ := json.Marshal(value)
+	return dat, err
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+	"github.com/tmax-cloud/alarm-operator/pkg/heartbeat"
+)
+
+type heartbeatProbeValue struct {
+	LastPing             string `json:"lastPing,omitempty"`
+	SecondsSinceLastPing int64  `json:"secondsSinceLastPing"`
+}
+
+// registerHeartbeat makes sure the notifier accepts the token of
+// status.pingURL. The token is generated here and saved in the status before
+// it's registered, so it can't be lost. Registering is idempotent and runs on
+// every reconcile, and a stale registration left by a deleted monitor of the
+// same name is replaced.
+func (r *MonitorReconciler) registerHeartbeat(ctx context.Context, o *tmaxiov1alpha1.Monitor) error {
+	id := extractId(o.Name, o.Namespace)
+	if o.Status.PingURL == "" {
+		token, err := heartbeat.NewToken()
+		if err != nil {
+			return err
+		}
+		o.Status.PingURL = notifier.PingURL(id, token)
+		if err := r.Status().Update(ctx, o); err != nil {
+			return err
+		}
+	}
+
+	token := path.Base(o.Status.PingURL)
+	err := notifier.RegisterHeartbeat(id, token)
+	if err == heartbeat.ErrRegistered {
+		if err := notifier.UnregisterHeartbeat(id); err != nil {
+			return err
+		}
+		err = notifier.RegisterHeartbeat(id, token)
+	}
+	return err
+}
+
+// probeHeartbeat asks the notifier for the last ping and fails when it is
+// older than the period plus grace. Until the first ping, the time is counted
+// from the creation of the monitor.
+func probeHeartbeat(o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec.Heartbeat
+	last, err := notifier.LastPing(extractId(o.Name, o.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	value := heartbeatProbeValue{}
+	since := o.CreationTimestamp.Time
+	if !last.IsZero() {
+		value.LastPing = last.Format(time.RFC3339)
+		since = last
+	}
+	elapsed := time.Since(since)
+	value.SecondsSinceLastPing = int64(elapsed / time.Second)
+
+	dat, _ := json.Marshal(value)
+	if elapsed > time.Duration(spec.Period+spec.Grace)*time.Second {
+		return dat, fmt.Errorf("no ping for %d seconds", value.SecondsSinceLastPing)
+	}
+	return dat, nil
+}
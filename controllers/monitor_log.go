@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+const defaultLogSamples = 5
+
+type logProbeValue struct {
+	Matches int      `json:"matches"`
+	Samples []string `json:"samples"`
+}
+
+// probeLog reads the logs the selected pods wrote during the last interval and
+// counts the lines matching the pattern. Pods whose logs can't be read fail
+// the probe, but the lines read from the other pods are still counted.
+func (r *MonitorReconciler) probeLog(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec.Log
+	pattern, err := regexp.Compile(spec.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	maxSamples := spec.Samples
+	if maxSamples <= 0 {
+		maxSamples = defaultLogSamples
+	}
+
+	start := time.Now()
+	defer func() { result.LatencyMs = msSince(start) }()
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(o.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	since := int64(o.Spec.Interval)
+	value := logProbeValue{Samples: []string{}}
+	var lastErr error
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		opts := &corev1.PodLogOptions{Container: spec.Container, SinceSeconds: &since}
+		stream, err := r.Clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read logs of %s: %v", pod.Name, err)
+			continue
+		}
+		scanner := bufio.NewScanner(stream)
+		for scanner.Scan() {
+			line := scanner.Text()
+			result.Size += int64(len(line))
+			if !pattern.MatchString(line) {
+				continue
+			}
+			value.Matches++
+			if len(value.Samples) < maxSamples {
+				value.Samples = append(value.Samples, pod.Name+": "+line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			lastErr = fmt.Errorf("failed to read logs of %s: %v", pod.Name, err)
+		}
+		stream.Close()
+	}
+
+	dat, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return dat, lastErr
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+// probeObject reads the referenced object, or the objects matching the
+// selector, in the monitor's namespace. Objects are read from the API server
+// so that no informer is started for the kind, and Secrets are refused since
+// the value is exposed in the status of the monitor.
+func (r *MonitorReconciler) probeObject(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec.Object
+	gv, err := schema.ParseGroupVersion(spec.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	if gv.Group == "" && spec.Kind == "Secret" {
+		return nil, fmt.Errorf("reading Secret is not allowed")
+	}
+	if spec.Namespace != "" && spec.Namespace != o.Namespace {
+		return nil, fmt.Errorf("object must be in the monitor's namespace %s", o.Namespace)
+	}
+	namespace := o.Namespace
+
+	start := time.Now()
+	defer func() { result.LatencyMs = msSince(start) }()
+
+	var obj runtime.Object
+	switch {
+	case spec.Name != "":
+		obj, err = r.getObject(ctx, gv.WithKind(spec.Kind), types.NamespacedName{Namespace: namespace, Name: spec.Name})
+	case spec.Selector != nil:
+		obj, err = r.listObjects(ctx, gv.WithKind(spec.Kind+"List"), namespace, spec.Selector)
+	default:
+		err = fmt.Errorf("one of name or selector is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dat, err := json.Marshal(obj)
+	result.Size = int64(len(dat))
+	return dat, err
+}
+
+func (r *MonitorReconciler) newObject(gvk schema.GroupVersionKind) runtime.Object {
+	if obj, err := r.Scheme.New(gvk); err == nil {
+		return obj
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+func (r *MonitorReconciler) getObject(ctx context.Context, gvk schema.GroupVersionKind, name types.NamespacedName) (runtime.Object, error) {
+	obj := r.newObject(gvk)
+	if err := r.APIReader.Get(ctx, name, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (r *MonitorReconciler) listObjects(ctx context.Context, gvk schema.GroupVersionKind, namespace string, selector *metav1.LabelSelector) (runtime.Object, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	list := r.newObject(gvk)
+	if _, ok := list.(*unstructured.Unstructured); ok {
+		u := &unstructured.UnstructuredList{}
+		u.SetGroupVersionKind(gvk)
+		list = u
+	}
+	if err := r.APIReader.List(ctx, list, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
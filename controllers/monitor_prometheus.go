@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+type prometheusSeries struct {
+	Labels map[string]string `json:"labels"`
+	Value  interface{}       `json:"value"`
+}
+
+// probePrometheus executes the instant query and converts the resulting
+// vector, scalar or string into a list of series.
+func (r *MonitorReconciler) probePrometheus(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := o.Spec
+	spec.URL = strings.TrimSuffix(o.Spec.Prometheus.URL, "/") + "/api/v1/query?query=" + url.QueryEscape(o.Spec.Prometheus.Query)
+	spec.Method = http.MethodGet
+	spec.Body = ""
+
+	cli, err := r.httpClient(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := r.authHeaders(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	dat, _, err := fetchResource(ctx, cli, spec, headers, result)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := prometheusResponse{}
+	if err := json.Unmarshal(dat, &resp); err != nil {
+		return dat, fmt.Errorf("invalid response (status code: %d): %v", result.StatusCode, err)
+	}
+	if resp.Status != "success" {
+		return dat, fmt.Errorf("query failed: %s", resp.Error)
+	}
+
+	series, err := parsePrometheusResult(resp.Data.ResultType, resp.Data.Result)
+	if err != nil {
+		return dat, err
+	}
+	return json.Marshal(series)
+}
+
+func parsePrometheusResult(resultType string, raw json.RawMessage) ([]prometheusSeries, error) {
+	ret := []prometheusSeries{}
+	switch resultType {
+	case "vector":
+		samples := []prometheusSample{}
+		if err := json.Unmarshal(raw, &samples); err != nil {
+			return nil, err
+		}
+		for _, sample := range samples {
+			ret = append(ret, prometheusSeries{Labels: sample.Metric, Value: sampleValue(sample.Value)})
+		}
+	case "scalar", "string":
+		var value [2]interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		ret = append(ret, prometheusSeries{Labels: map[string]string{}, Value: sampleValue(value)})
+	default:
+		return nil, fmt.Errorf("unsupported result type: %s", resultType)
+	}
+	return ret, nil
+}
+
+// sampleValue converts the sample value to a number, keeping strings that
+// can't be represented in JSON such as NaN or +Inf.
+func sampleValue(value [2]interface{}) interface{} {
+	str, ok := value[1].(string)
+	if !ok {
+		return value[1]
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return str
+	}
+	return f
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"sigs.k8s.io/yaml"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+// parseResponse normalises the response body into a JSON document according
+// to the response format of the monitor.
+func parseResponse(spec tmaxiov1alpha1.MonitorSpec, dat []byte) ([]byte, error) {
+	switch spec.ResponseFormat {
+	case "", tmaxiov1alpha1.ResponseFormatJSON:
+		return dat, nil
+	case tmaxiov1alpha1.ResponseFormatYAML:
+		return yaml.YAMLToJSON(dat)
+	case tmaxiov1alpha1.ResponseFormatXML:
+		doc, err := parseXML(dat)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	case tmaxiov1alpha1.ResponseFormatPrometheus:
+		doc, err := parsePrometheusText(dat)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	case tmaxiov1alpha1.ResponseFormatText:
+		doc, err := parseText(spec.ResponsePattern, dat)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	}
+	return nil, fmt.Errorf("unknown response format: %s", spec.ResponseFormat)
+}
+
+// parseXML converts the document into {"<root>": element}. An element is
+// a map of attributes ("@name"), child elements and text ("#text"), or just
+// its text when it has neither attributes nor children. Repeated child
+// elements become a list.
+func parseXML(dat []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(dat))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			v, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: v}, nil
+		}
+	}
+}
+
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := map[string]interface{}{}
+	for _, attr := range start.Attr {
+		element["@"+attr.Name.Local] = attr.Value
+	}
+
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			switch prev := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(prev, child)
+			default:
+				element[name] = []interface{}{prev, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				element["#text"] = s
+			}
+			return element, nil
+		}
+	}
+}
+
+type metricSample struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  interface{}       `json:"value"`
+}
+
+// parsePrometheusText converts the text exposition format into a map of
+// sample name to its samples. Histograms and summaries are split into the
+// _bucket, _sum and _count samples as they are exposed.
+func parsePrometheusText(dat []byte) (map[string][]metricSample, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(dat))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string][]metricSample{}
+	add := func(name string, m *dto.Metric, v float64, extra ...string) {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			labels[extra[i]] = extra[i+1]
+		}
+		doc[name] = append(doc[name], metricSample{Labels: labels, Value: sampleFloat(v)})
+	}
+
+	for name, family := range families {
+		for _, m := range family.GetMetric() {
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m, m.GetGauge().GetValue())
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().GetQuantile() {
+					add(name, m, q.GetValue(), "quantile", fmt.Sprint(q.GetQuantile()))
+				}
+				add(name+"_sum", m, m.GetSummary().GetSampleSum())
+				add(name+"_count", m, float64(m.GetSummary().GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				for _, b := range m.GetHistogram().GetBucket() {
+					add(name+"_bucket", m, float64(b.GetCumulativeCount()), "le", fmt.Sprint(b.GetUpperBound()))
+				}
+				add(name+"_sum", m, m.GetHistogram().GetSampleSum())
+				add(name+"_count", m, float64(m.GetHistogram().GetSampleCount()))
+			default:
+				add(name, m, m.GetUntyped().GetValue())
+			}
+		}
+	}
+	return doc, nil
+}
+
+// sampleFloat keeps NaN and infinities, which JSON can't represent, as strings.
+func sampleFloat(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Sprint(v)
+	}
+	return v
+}
+
+// parseText matches the pattern against the body and returns its named
+// capture groups of the first match.
+func parseText(pattern string, dat []byte) (map[string]string, error) {
+	if pattern == "" {
+		return map[string]string{"text": string(dat)}, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	match := re.FindSubmatch(dat)
+	if match == nil {
+		return nil, fmt.Errorf("response doesn't match pattern %s", pattern)
+	}
+	doc := map[string]string{}
+	for i, name := range re.SubexpNames() {
+		if i > 0 && name != "" {
+			doc[name] = string(match[i])
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestParseXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		want    string
+		wantErr bool
+	}{
+		{"text", `<status>UP</status>`, `{"status":"UP"}`, false},
+		{"empty", `<status/>`, `{"status":""}`, false},
+		{"children", `<health><db>UP</db><cache>DOWN</cache></health>`, `{"health":{"cache":"DOWN","db":"UP"}}`, false},
+		{"repeated", `<list><item>a</item><item>b</item><item>c</item></list>`, `{"list":{"item":["a","b","c"]}}`, false},
+		{"attributes and text", `<check name="db" ok="true">fine</check>`, `{"check":{"#text":"fine","@name":"db","@ok":"true"}}`, false},
+		{"repeated with attributes", `<r><c id="1"/><c id="2">x</c></r>`, `{"r":{"c":[{"@id":"1"},{"#text":"x","@id":"2"}]}}`, false},
+		{"prolog", "<?xml version=\"1.0\"?>\n<!-- comment -->\n<status>UP</status>", `{"status":"UP"}`, false},
+		{"unclosed", `<health><db>UP</db>`, ``, true},
+		{"empty body", ``, ``, true},
+	}
+	for _, tt := range tests {
+		doc, err := parseXML([]byte(tt.xml))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseXML() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got, _ := json.Marshal(doc); string(got) != tt.want {
+			t.Errorf("%s: parseXML() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrometheusText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			"counter and gauge",
+			"# TYPE http_requests_total counter\nhttp_requests_total{code=\"200\"} 10\nhttp_requests_total{code=\"500\"} 2\n# TYPE up gauge\nup 1\n",
+			`{"http_requests_total":[{"labels":{"code":"200"},"value":10},{"labels":{"code":"500"},"value":2}],"up":[{"value":1}]}`,
+			false,
+		},
+		{
+			"untyped",
+			"queue_length 3\n",
+			`{"queue_length":[{"value":3}]}`,
+			false,
+		},
+		{
+			"histogram",
+			"# TYPE latency histogram\nlatency_bucket{le=\"0.1\"} 3\nlatency_bucket{le=\"+Inf\"} 5\nlatency_sum 1.5\nlatency_count 5\n",
+			`{"latency_bucket":[{"labels":{"le":"0.1"},"value":3},{"labels":{"le":"+Inf"},"value":5}],"latency_count":[{"value":5}],"latency_sum":[{"value":1.5}]}`,
+			false,
+		},
+		{
+			"summary",
+			"# TYPE rpc summary\nrpc{quantile=\"0.5\"} 0.2\nrpc{quantile=\"0.99\"} 0.9\nrpc_sum 12\nrpc_count 40\n",
+			`{"rpc":[{"labels":{"quantile":"0.5"},"value":0.2},{"labels":{"quantile":"0.99"},"value":0.9}],"rpc_count":[{"value":40}],"rpc_sum":[{"value":12}]}`,
+			false,
+		},
+		{
+			"nan and inf",
+			"# TYPE temp gauge\ntemp{sensor=\"a\"} NaN\ntemp{sensor=\"b\"} +Inf\ntemp{sensor=\"c\"} -Inf\n",
+			`{"temp":[{"labels":{"sensor":"a"},"value":"NaN"},{"labels":{"sensor":"b"},"value":"+Inf"},{"labels":{"sensor":"c"},"value":"-Inf"}]}`,
+			false,
+		},
+		{"empty", "", `{}`, false},
+		{"invalid", "up{ 1\n", ``, true},
+	}
+	for _, tt := range tests {
+		doc, err := parsePrometheusText([]byte(tt.text))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parsePrometheusText() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got, _ := json.Marshal(doc); string(got) != tt.want {
+			t.Errorf("%s: parsePrometheusText() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseText(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"no pattern", "", "OK\n", `{"text":"OK\n"}`, false},
+		{"named groups", `connections: (?P<active>\d+) active, (?P<idle>\d+) idle`, "connections: 3 active, 7 idle", `{"active":"3","idle":"7"}`, false},
+		{"first match", `load=(?P<load>[\d.]+)`, "load=0.5\nload=0.9", `{"load":"0.5"}`, false},
+		{"unnamed groups ignored", `(\w+)=(?P<value>\d+)`, "count=4", `{"value":"4"}`, false},
+		{"optional group", `status=(?P<status>\w+)(?: code=(?P<code>\d+))?`, "status=UP", `{"code":"","status":"UP"}`, false},
+		{"no match", `status=(?P<status>\w+)`, "unavailable", ``, true},
+		{"invalid pattern", `(?P<status>`, "status=UP", ``, true},
+	}
+	for _, tt := range tests {
+		doc, err := parseText(tt.pattern, []byte(tt.text))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseText() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got, _ := json.Marshal(doc); string(got) != tt.want {
+			t.Errorf("%s: parseText() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    tmaxiov1alpha1.MonitorSpec
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"json", tmaxiov1alpha1.MonitorSpec{}, `{"status":"UP"}`, `{"status":"UP"}`, false},
+		{"yaml", tmaxiov1alpha1.MonitorSpec{ResponseFormat: tmaxiov1alpha1.ResponseFormatYAML}, "status: UP\nchecks:\n- db\n", `{"checks":["db"],"status":"UP"}`, false},
+		{"unknown", tmaxiov1alpha1.MonitorSpec{ResponseFormat: "csv"}, "a,b", ``, true},
+	}
+	for _, tt := range tests {
+		got, err := parseResponse(tt.spec, []byte(tt.body))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseResponse() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && string(got) != tt.want {
+			t.Errorf("%s: parseResponse() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
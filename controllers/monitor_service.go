@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type endpointResult struct {
+	Address    string `json:"address"`
+	Pod        string `json:"pod,omitempty"`
+	Status     string `json:"status"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// probeService fetches the Service's URL, or every ready endpoint of it when
+// perEndpoint is set. The value of a per endpoint probe is
+// {"endpoints": [...]} holding the result of each endpoint.
+func (r *MonitorReconciler) probeService(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	ref := o.Spec.ServiceRef
+	name := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if name.Namespace == "" {
+		name.Namespace = o.Namespace
+	}
+	scheme := ref.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, name, svc); err != nil {
+		return nil, err
+	}
+	port, err := servicePort(svc, ref.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ref.PerEndpoint {
+		host := fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+		return r.fetch(ctx, o, serviceURL(scheme, host, port.Port, ref.Path), result)
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := r.Get(ctx, name, endpoints); err != nil {
+		return nil, err
+	}
+
+	results := []endpointResult{}
+	failed := 0
+	for _, subset := range endpoints.Subsets {
+		var target int32
+		for _, p := range subset.Ports {
+			if p.Name == port.Name {
+				target = p.Port
+			}
+		}
+		if target == 0 {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			epResult := tmaxiov1alpha1.MonitorResult{}
+			_, err := r.fetch(ctx, o, serviceURL(scheme, addr.IP, target, ref.Path), &epResult)
+			ep := endpointResult{
+				Address:    net.JoinHostPort(addr.IP, strconv.Itoa(int(target))),
+				Status:     "Success",
+				StatusCode: epResult.StatusCode,
+				LatencyMs:  epResult.LatencyMs,
+			}
+			if addr.TargetRef != nil {
+				ep.Pod = addr.TargetRef.Name
+			}
+			if err != nil {
+				ep.Status = "Fail"
+				ep.Error = err.Error()
+				failed++
+			}
+			if epResult.LatencyMs > result.LatencyMs {
+				result.LatencyMs = epResult.LatencyMs
+			}
+			results = append(results, ep)
+		}
+	}
+
+	dat, err := json.Marshal(map[string][]endpointResult{"endpoints": results})
+	if err != nil {
+		return nil, err
+	}
+	result.Size = int64(len(dat))
+	switch {
+	case len(results) == 0:
+		return dat, fmt.Errorf("no ready endpoint of service %s", name)
+	case failed > 0:
+		return dat, fmt.Errorf("%d of %d endpoints failed", failed, len(results))
+	}
+	return dat, nil
+}
+
+func servicePort(svc *corev1.Service, port string) (corev1.ServicePort, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return corev1.ServicePort{}, fmt.Errorf("service %s has no port", svc.Name)
+	}
+	if port == "" {
+		return svc.Spec.Ports[0], nil
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Name == port || strconv.Itoa(int(p.Port)) == port {
+			return p, nil
+		}
+	}
+	return corev1.ServicePort{}, fmt.Errorf("port %s not found in service %s", port, svc.Name)
+}
+
+func serviceURL(scheme, host string, port int32, path string) string {
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(port))), path)
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+	"github.com/tmax-cloud/alarm-operator/pkg/evaluator"
+)
+
+var variableRegex = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// probeSteps runs the steps in order and stops at the first failed one. The
+// value is the response body of the last executed step.
+func (r *MonitorReconciler) probeSteps(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	cli, err := r.httpClient(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	authHeaders, err := r.authHeaders(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]string{}
+	var dat []byte
+	for _, step := range o.Spec.Steps {
+		spec := tmaxiov1alpha1.MonitorSpec{
+			Method:  step.Method,
+			URL:     substitute(step.URL, vars),
+			Body:    substitute(step.Body, vars),
+			Headers: map[string]string{},
+		}
+		for k, v := range o.Spec.Headers {
+			spec.Headers[k] = substitute(v, vars)
+		}
+		for k, v := range step.Headers {
+			spec.Headers[k] = substitute(v, vars)
+		}
+
+		stepResult := tmaxiov1alpha1.MonitorResult{}
+		var header http.Header
+		dat, header, err = fetchResource(ctx, cli, spec, authHeaders, &stepResult)
+		if err == nil && !isExpectedStatus(stepResult.StatusCode, step.ExpectedStatusCodes) {
+			err = fmt.Errorf("unexpected status code: %d", stepResult.StatusCode)
+		}
+		if err == nil {
+			err = checkStep(step, dat, header, vars)
+		}
+
+		result.StatusCode = stepResult.StatusCode
+		result.LatencyMs += stepResult.LatencyMs
+		result.Timing = stepResult.Timing
+		result.Size = stepResult.Size
+		sr := tmaxiov1alpha1.MonitorStepResult{
+			Name:       step.Name,
+			Status:     "Success",
+			StatusCode: stepResult.StatusCode,
+			LatencyMs:  stepResult.LatencyMs,
+		}
+		if err != nil {
+			sr.Status = "Fail"
+			sr.Error = err.Error()
+			result.FailedStep = step.Name
+		}
+		result.Steps = append(result.Steps, sr)
+		if err != nil {
+			return dat, fmt.Errorf("step %s failed: %v", step.Name, err)
+		}
+	}
+	return dat, nil
+}
+
+// checkStep evaluates the assertions of the step and extracts its variables.
+func checkStep(step tmaxiov1alpha1.HTTPStep, dat []byte, header http.Header, vars map[string]string) error {
+	var parsed *gabs.Container
+	body := func() (*gabs.Container, error) {
+		if parsed != nil {
+			return parsed, nil
+		}
+		var err error
+		parsed, err = gabs.ParseJSON(dat)
+		return parsed, err
+	}
+
+	for _, a := range step.Assertions {
+		doc, err := body()
+		if err != nil {
+			return err
+		}
+		matched, err := evaluator.Eval(doc.Path(a.FieldPath).Data(), a.Op, a.Operand)
+		if err != nil {
+			return fmt.Errorf("assertion %s %s %s: %v", a.FieldPath, a.Op, a.Operand, err)
+		}
+		if !matched {
+			return fmt.Errorf("assertion %s %s %s not matched", a.FieldPath, a.Op, a.Operand)
+		}
+	}
+
+	for name, from := range step.Extract {
+		tokens := strings.SplitN(from, ":", 2)
+		if len(tokens) != 2 {
+			return fmt.Errorf("invalid extract source: %s", from)
+		}
+		switch tokens[0] {
+		case "header":
+			vars[name] = header.Get(tokens[1])
+		case "body":
+			doc, err := body()
+			if err != nil {
+				return err
+			}
+			v := doc.Path(tokens[1]).Data()
+			if v == nil {
+				return fmt.Errorf("field %s not found in response", tokens[1])
+			}
+			if str, ok := v.(string); ok {
+				vars[name] = str
+			} else {
+				vars[name] = doc.Path(tokens[1]).String()
+			}
+		default:
+			return fmt.Errorf("invalid extract source: %s", from)
+		}
+	}
+	return nil
+}
+
+func substitute(s string, vars map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[variableRegex.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+		return m
+	})
+}
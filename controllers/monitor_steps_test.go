@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestSubstitute(t *testing.T) {
+	vars := map[string]string{"token": "abc", "id": "42", "empty": ""}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"no variables", "no variables"},
+		{"Bearer ${token}", "Bearer abc"},
+		{"/items/${id}?token=${token}", "/items/42?token=abc"},
+		{"${id}${id}", "4242"},
+		{"[${empty}]", "[]"},
+		{"${unknown} stays", "${unknown} stays"},
+		{"$token and ${token", "$token and ${token"},
+	}
+	for _, tt := range tests {
+		if got := substitute(tt.in, vars); got != tt.want {
+			t.Errorf("substitute(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStep(t *testing.T) {
+	body := []byte(`{"token": "abc", "user": {"id": 42, "roles": ["admin"]}, "status": "UP", "latency": 120}`)
+	header := http.Header{}
+	header.Set("Location", "/items/7")
+
+	tests := []struct {
+		name     string
+		step     tmaxiov1alpha1.HTTPStep
+		dat      []byte
+		wantVars map[string]string
+		wantErr  bool
+	}{
+		{"nothing to check", tmaxiov1alpha1.HTTPStep{}, []byte("not json"), map[string]string{}, false},
+		{
+			"extract body string",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"token": "body:token"}},
+			body, map[string]string{"token": "abc"}, false,
+		},
+		{
+			"extract body number and object",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"id": "body:user.id", "roles": "body:user.roles"}},
+			body, map[string]string{"id": "42", "roles": `["admin"]`}, false,
+		},
+		{
+			"extract header",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"location": "header:Location", "missing": "header:X-Missing"}},
+			body, map[string]string{"location": "/items/7", "missing": ""}, false,
+		},
+		{
+			"extract missing field",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"id": "body:user.missing"}},
+			body, map[string]string{}, true,
+		},
+		{
+			"extract invalid source",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"id": "cookie:session"}},
+			body, map[string]string{}, true,
+		},
+		{
+			"extract without source",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"id": "token"}},
+			body, map[string]string{}, true,
+		},
+		{
+			"extract body from non json",
+			tmaxiov1alpha1.HTTPStep{Extract: map[string]string{"token": "body:token"}},
+			[]byte("<html>"), map[string]string{}, true,
+		},
+		{
+			"assertions matched",
+			tmaxiov1alpha1.HTTPStep{Assertions: []tmaxiov1alpha1.StepAssertion{
+				{FieldPath: "status", Op: "eq", Operand: "UP"},
+				{FieldPath: "latency", Op: "lt", Operand: "500"},
+			}},
+			body, map[string]string{}, false,
+		},
+		{
+			"assertion not matched",
+			tmaxiov1alpha1.HTTPStep{Assertions: []tmaxiov1alpha1.StepAssertion{{FieldPath: "status", Op: "eq", Operand: "DOWN"}}},
+			body, map[string]string{}, true,
+		},
+		{
+			"assertion error",
+			tmaxiov1alpha1.HTTPStep{Assertions: []tmaxiov1alpha1.StepAssertion{{FieldPath: "status", Op: "gt", Operand: "one"}}},
+			body, map[string]string{}, true,
+		},
+		{
+			"assertion on non json",
+			tmaxiov1alpha1.HTTPStep{Assertions: []tmaxiov1alpha1.StepAssertion{{FieldPath: "status", Op: "exists"}}},
+			[]byte("OK"), map[string]string{}, true,
+		},
+		{
+			"failed assertion skips extraction",
+			tmaxiov1alpha1.HTTPStep{
+				Assertions: []tmaxiov1alpha1.StepAssertion{{FieldPath: "status", Op: "eq", Operand: "DOWN"}},
+				Extract:    map[string]string{"token": "body:token"},
+			},
+			body, map[string]string{}, true,
+		},
+	}
+	for _, tt := range tests {
+		vars := map[string]string{}
+		err := checkStep(tt.step, tt.dat, header, vars)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkStep() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(vars, tt.wantVars) {
+			t.Errorf("%s: vars = %v, want %v", tt.name, vars, tt.wantVars)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+const tcpReadLimit = 4096
+
+type tcpProbeValue struct {
+	Connected bool   `json:"connected"`
+	Response  string `json:"response,omitempty"`
+}
+
+// probeTCP connects to the address and, if configured, sends the banner and
+// waits for the expected bytes. The value is a JSON object of tcpProbeValue.
+func probeTCP(ctx context.Context, o *tmaxiov1alpha1.Monitor, result *tmaxiov1alpha1.MonitorResult) ([]byte, error) {
+	spec := *o.Spec.TCP
+	if spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(spec.Timeout)*time.Second)
+		defer cancel()
+	}
+	// a server that never sends the expected bytes would block the read forever
+	ctx, cancel := withDefaultTimeout(ctx, o)
+	defer cancel()
+
+	value := tcpProbeValue{}
+	start := time.Now()
+	defer func() { result.LatencyMs = msSince(start) }()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", spec.Address)
+	if err != nil {
+		dat, _ := json.Marshal(value)
+		return dat, err
+	}
+	defer conn.Close()
+	value.Connected = true
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	err = exchangeBanner(conn, spec, &value)
+	result.Size = int64(len(value.Response))
+
+	dat, _ := json.Marshal(value)
+	return dat, err
+}
+
+func exchangeBanner(conn net.Conn, spec tmaxiov1alpha1.TCPProbe, value *tcpProbeValue) error {
+	if spec.Send != "" {
+		if _, err := conn.Write([]byte(spec.Send)); err != nil {
+			return err
+		}
+	}
+	if spec.Expect == "" {
+		return nil
+	}
+
+	buf := make([]byte, 0, tcpReadLimit)
+	chunk := make([]byte, 512)
+	for len(buf) < tcpReadLimit {
+		n, err := conn.Read(chunk)
+		buf = append(buf, chunk[:n]...)
+		value.Response = string(buf)
+		if bytes.Contains(buf, []byte(spec.Expect)) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("expected response not received: %v", err)
+		}
+	}
+	return fmt.Errorf("expected response not received in %d bytes", tcpReadLimit)
+}
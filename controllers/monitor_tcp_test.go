@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestProbeTCPTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		// accept but never answer
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	for name, o := range map[string]*tmaxiov1alpha1.Monitor{
+		"tcp timeout": {Spec: tmaxiov1alpha1.MonitorSpec{Interval: 60,
+			TCP: &tmaxiov1alpha1.TCPProbe{Address: l.Addr().String(), Expect: "+PONG", Timeout: 1}}},
+		"interval": {Spec: tmaxiov1alpha1.MonitorSpec{Interval: 1,
+			TCP: &tmaxiov1alpha1.TCPProbe{Address: l.Addr().String(), Expect: "+PONG"}}},
+	} {
+		start := time.Now()
+		dat, err := probeTCP(context.Background(), o, &tmaxiov1alpha1.MonitorResult{})
+		if err == nil {
+			t.Errorf("%s: probeTCP() succeeded without the expected response", name)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: probeTCP() took %v", name, elapsed)
+		}
+		if string(dat) != `{"connected":true}` {
+			t.Errorf("%s: probeTCP() = %s", name, dat)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+type cachedClient struct {
+	version string
+	client  *http.Client
+}
+
+// clientCache keeps one http client per monitor, rebuilt when the TLS settings
+// or the referenced objects change.
+type clientCache struct {
+	mutex   sync.Mutex
+	clients map[string]cachedClient
+}
+
+var clients = &clientCache{clients: make(map[string]cachedClient)}
+
+func (c *clientCache) get(key, version string) (*http.Client, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cached, ok := c.clients[key]
+	if !ok || cached.version != version {
+		return nil, false
+	}
+	return cached.client, true
+}
+
+func (c *clientCache) set(key, version string, client *http.Client) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.clients[key] = cachedClient{version: version, client: client}
+}
+
+func (c *clientCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if cached, ok := c.clients[key]; ok {
+		cached.client.CloseIdleConnections()
+		delete(c.clients, key)
+	}
+}
+
+// httpClient returns the http client for the monitor. Monitors without TLS
+// settings share the default client.
+func (r *MonitorReconciler) httpClient(ctx context.Context, o *tmaxiov1alpha1.Monitor) (*http.Client, error) {
+	if o.Spec.TLS == nil {
+		return httpcli, nil
+	}
+
+	m, err := r.readTLSMaterial(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	key := path.Join(o.Namespace, o.Name)
+	if cli, ok := clients.get(key, m.version); ok {
+		return cli, nil
+	}
+
+	cfg, err := buildTLSConfig(o.Spec.TLS, m.ca, m.cert)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	cli := &http.Client{Transport: transport}
+
+	clients.remove(key)
+	clients.set(key, m.version, cli)
+	return cli, nil
+}
+
+// tlsConfig builds the TLS client config of the monitor for non-HTTP probes.
+func (r *MonitorReconciler) tlsConfig(ctx context.Context, o *tmaxiov1alpha1.Monitor) (*tls.Config, error) {
+	if o.Spec.TLS == nil {
+		return &tls.Config{}, nil
+	}
+	m, err := r.readTLSMaterial(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	return buildTLSConfig(o.Spec.TLS, m.ca, m.cert)
+}
+
+type tlsMaterial struct {
+	ca   []byte
+	cert *corev1.Secret
+	// version changes whenever the settings or the referenced objects change.
+	version string
+}
+
+// tlsVersion identifies the TLS settings by their values. The resource
+// versions of the referenced objects are appended by readTLSMaterial.
+func tlsVersion(spec *tmaxiov1alpha1.MonitorTLS) string {
+	ca := ""
+	if spec.CA != nil {
+		ca = path.Join(spec.CA.Kind, spec.CA.Name, spec.CA.Key)
+	}
+	return fmt.Sprintf("ca=%s,cert=%s,serverName=%s,insecure=%t", ca, spec.ClientCertSecret, spec.ServerName, spec.InsecureSkipVerify)
+}
+
+func (r *MonitorReconciler) readTLSMaterial(ctx context.Context, o *tmaxiov1alpha1.Monitor) (tlsMaterial, error) {
+	spec := o.Spec.TLS
+	m := tlsMaterial{version: tlsVersion(spec)}
+	if spec.CA != nil {
+		data, resourceVersion, err := r.readKey(ctx, o.Namespace, *spec.CA)
+		if err != nil {
+			return m, err
+		}
+		m.ca = data
+		m.version += "/" + resourceVersion
+	}
+	if spec.ClientCertSecret != "" {
+		m.cert = &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: o.Namespace, Name: spec.ClientCertSecret}, m.cert); err != nil {
+			return m, err
+		}
+		m.version += "/" + m.cert.ResourceVersion
+	}
+	return m, nil
+}
+
+func (r *MonitorReconciler) readKey(ctx context.Context, namespace string, sel tmaxiov1alpha1.KeySelector) ([]byte, string, error) {
+	name := types.NamespacedName{Namespace: namespace, Name: sel.Name}
+	switch sel.Kind {
+	case "Secret":
+		o := &corev1.Secret{}
+		if err := r.Get(ctx, name, o); err != nil {
+			return nil, "", err
+		}
+		if data, ok := o.Data[sel.Key]; ok {
+			return data, o.ResourceVersion, nil
+		}
+	case "ConfigMap":
+		o := &corev1.ConfigMap{}
+		if err := r.Get(ctx, name, o); err != nil {
+			return nil, "", err
+		}
+		if data, ok := o.Data[sel.Key]; ok {
+			return []byte(data), o.ResourceVersion, nil
+		}
+	default:
+		return nil, "", fmt.Errorf("unsupported kind: %s", sel.Kind)
+	}
+	return nil, "", fmt.Errorf("key %s not found in %s %s", sel.Key, sel.Kind, sel.Name)
+}
+
+func buildTLSConfig(spec *tmaxiov1alpha1.MonitorTLS, ca []byte, cert *corev1.Secret) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         spec.ServerName,
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+	if ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificate in CA bundle")
+		}
+		cfg.RootCAs = pool
+	}
+	if cert != nil {
+		pair, err := tls.X509KeyPair(cert.Data[corev1.TLSCertKey], cert.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"testing"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+)
+
+func TestTLSVersion(t *testing.T) {
+	spec := func(ca string, serverName string, insecure bool) *tmaxiov1alpha1.MonitorTLS {
+		return &tmaxiov1alpha1.MonitorTLS{
+			CA:                 &tmaxiov1alpha1.KeySelector{Kind: "ConfigMap", Name: ca, Key: "ca.crt"},
+			ClientCertSecret:   "client",
+			ServerName:         serverName,
+			InsecureSkipVerify: insecure,
+		}
+	}
+
+	base := tlsVersion(spec("ca", "example.com", false))
+	if got := tlsVersion(spec("ca", "example.com", false)); got != base {
+		t.Errorf("version of equal settings in another object = %s, want %s", got, base)
+	}
+	for name, changed := range map[string]*tmaxiov1alpha1.MonitorTLS{
+		"ca":         spec("other-ca", "example.com", false),
+		"serverName": spec("ca", "example.org", false),
+		"insecure":   spec("ca", "example.com", true),
+		"no ca":      {ClientCertSecret: "client", ServerName: "example.com"},
+	} {
+		if tlsVersion(changed) == base {
+			t.Errorf("version doesn't change with %s", name)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+	"github.com/tmax-cloud/alarm-operator/pkg/evaluator"
 )
 
 // NotificationTriggerReconciler reconciles a NotificationTrigger object
@@ -48,6 +49,7 @@ func (r *NotificationTriggerReconciler) Reconcile(req ctrl.Request) (ctrl.Result
 	err := r.Client.Get(ctx, req.NamespacedName, o)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			conditions.Remove(req.NamespacedName.String())
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -70,6 +72,20 @@ func (r *NotificationTriggerReconciler) Reconcile(req ctrl.Request) (ctrl.Result
 			}
 		}
 
+		conditionError := ""
+		if o.Spec.Condition != "" {
+			if _, err := evaluator.CompileCondition(o.Spec.Condition); err != nil {
+				logger.Error(err, "failed to compile condition")
+				conditionError = err.Error()
+			}
+		}
+		if o.Status.ConditionError != conditionError {
+			o.Status.ConditionError = conditionError
+			if err := r.Status().Update(ctx, o); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
 		path.Join(req.Namespace, req.Name)
 		if hasSubscribersAnnotation(monitor.ObjectMeta, o.ObjectMeta) {
 			logger.Info("already in subscribers", "subject", monitor.Name)
@@ -88,6 +104,7 @@ func (r *NotificationTriggerReconciler) Reconcile(req ctrl.Request) (ctrl.Result
 				return ctrl.Result{}, err
 			}
 
+			conditions.Remove(req.NamespacedName.String())
 			removeFinalizer(&o.ObjectMeta, finalizer)
 			if err := r.Update(ctx, o); err != nil {
 				return ctrl.Result{}, err
@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+	"github.com/tmax-cloud/alarm-operator/pkg/evaluator"
+	"github.com/tmax-cloud/alarm-operator/pkg/fieldpath"
+	"github.com/tmax-cloud/alarm-operator/pkg/notification"
+)
+
+// trigger evaluates the notification trigger against the monitor's latest
+// result and sends its notification when the condition matches. prev is the
+// parsed response of the previous run, if any.
+func (r *MonitorReconciler) trigger(ctx context.Context, name types.NamespacedName, mr tmaxiov1alpha1.MonitorResult, dat, prev []byte) error {
+	logger := r.Log.WithValues("trigger", name)
+
+	nt := &tmaxiov1alpha1.NotificationTrigger{}
+	if err := r.Get(ctx, name, nt); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var matched bool
+	var err error
+	if nt.Spec.Condition != "" {
+		matched, err = evalCondition(name.String(), nt.Spec.Condition, mr, dat, prev)
+	} else {
+		var v interface{}
+		if v, err = selectField(nt.Spec, mr, dat); err == nil {
+			logger.Info("parsed field", "value", v)
+			matched, err = compareField(nt, v, now)
+		}
+	}
+	if err != nil {
+		logger.Error(err, "failed to evaluate condition")
+	}
+
+	result, send := advance(nt, matched, err, now)
+	if send != "" {
+		if msg := r.notify(ctx, logger, nt, send); msg != "" {
+			result.Message = msg
+		} else if send == notification.StateFiring {
+			nt.Status.LastSent = now.Format(time.RFC3339)
+		}
+	}
+
+	nt.Status.History = append(nt.Status.History, result)
+	if len(nt.Status.History) > tmaxiov1alpha1.HistoryLimit {
+		start := len(nt.Status.History) - tmaxiov1alpha1.HistoryLimit
+		nt.Status.History = nt.Status.History[start:]
+	}
+
+	return r.Status().Update(ctx, nt)
+}
+
+// advance moves the trigger by the evaluation of a sample, and returns the
+// result to record and the notification to send, if any. An evaluation error
+// keeps the state, so an unreachable endpoint doesn't resolve a firing trigger.
+func advance(nt *tmaxiov1alpha1.NotificationTrigger, matched bool, evalErr error, now time.Time) (tmaxiov1alpha1.NotificationTriggerResult, notification.State) {
+	var send notification.State
+	result := tmaxiov1alpha1.NotificationTriggerResult{}
+	switch {
+	case evalErr != nil:
+		result.Message = fmt.Sprintf("failed to evaluate condition: %v", evalErr)
+	case matched && !holds(nt, now):
+		setState(nt, tmaxiov1alpha1.TriggerStatePending, now)
+		result.Message = fmt.Sprintf("condition pending (%d consecutive matches since %s)", nt.Status.Pending, nt.Status.PendingSince)
+	case matched:
+		firing := nt.Status.State == tmaxiov1alpha1.TriggerStateFiring
+		setState(nt, tmaxiov1alpha1.TriggerStateFiring, now)
+		if wait := suppressed(nt, firing, now); wait > 0 {
+			result.Message = fmt.Sprintf("notification suppressed for %s", wait.Round(time.Second))
+			break
+		}
+		result.Triggered = true
+		result.UpdatedAt = now.Format(time.RFC3339)
+		send = notification.StateFiring
+	default:
+		nt.Status.Pending = 0
+		nt.Status.PendingSince = ""
+		result.Message = fmt.Sprintf("condition not matched")
+		switch nt.Status.State {
+		case tmaxiov1alpha1.TriggerStateFiring:
+			notified := sentSince(nt.Status.LastSent, nt.Status.FiringSince)
+			setState(nt, tmaxiov1alpha1.TriggerStateResolved, now)
+			result.Message = "condition resolved"
+			if notified {
+				result.UpdatedAt = now.Format(time.RFC3339)
+				send = notification.StateResolved
+			}
+		case tmaxiov1alpha1.TriggerStatePending:
+			setState(nt, tmaxiov1alpha1.TriggerStateInactive, now)
+		}
+	}
+	result.State = nt.Status.State
+	return result, send
+}
+
+// setState moves the trigger to the state, recording the time of the transition.
+func setState(nt *tmaxiov1alpha1.NotificationTrigger, state tmaxiov1alpha1.TriggerState, now time.Time) {
+	if nt.Status.State == state {
+		return
+	}
+	nt.Status.State = state
+	nt.Status.LastTransitionTime = now.Format(time.RFC3339)
+	switch state {
+	case tmaxiov1alpha1.TriggerStateFiring:
+		nt.Status.FiringSince = nt.Status.LastTransitionTime
+		nt.Status.ResolvedAt = ""
+	case tmaxiov1alpha1.TriggerStateResolved:
+		nt.Status.ResolvedAt = nt.Status.LastTransitionTime
+		nt.Status.FiringSince = ""
+	}
+}
+
+// suppressed returns how long the firing notification has to wait for the
+// cooldown, or the repeat interval if the trigger was already firing.
+func suppressed(nt *tmaxiov1alpha1.NotificationTrigger, firing bool, now time.Time) time.Duration {
+	last, err := time.Parse(time.RFC3339, nt.Status.LastSent)
+	if err != nil {
+		return 0
+	}
+	wait := time.Duration(nt.Spec.Cooldown) * time.Second
+	if firing && nt.Spec.RepeatInterval > nt.Spec.Cooldown {
+		wait = time.Duration(nt.Spec.RepeatInterval) * time.Second
+	}
+	return last.Add(wait).Sub(now)
+}
+
+// sentSince reports whether the notification was sent at or after since.
+func sentSince(lastSent, since string) bool {
+	last, err := time.Parse(time.RFC3339, lastSent)
+	if err != nil {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	return err == nil && !last.Before(t)
+}
+
+// notify sends the notification of the trigger for the state, and returns
+// the reason of the failure if it couldn't be sent.
+func (r *MonitorReconciler) notify(ctx context.Context, logger logr.Logger, nt *tmaxiov1alpha1.NotificationTrigger, state notification.State) string {
+	n := &tmaxiov1alpha1.Notification{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: nt.Namespace, Name: nt.Spec.Notification}, n); err != nil {
+		logger.Error(err, "failed to get notification from resource")
+		return "failed to get notification from resource"
+	}
+	if err := sendNotification(*n, state); err != nil {
+		logger.Error(err, "failed to send notification")
+		return "failed to send notification"
+	}
+	return ""
+}
+
+// holds counts the match in the status of the trigger, and reports whether
+// the condition has matched for the consecutive samples and the duration
+// required by the trigger.
+func holds(nt *tmaxiov1alpha1.NotificationTrigger, now time.Time) bool {
+	nt.Status.Pending++
+	since, err := time.Parse(time.RFC3339, nt.Status.PendingSince)
+	if err != nil {
+		since = now
+		nt.Status.PendingSince = now.Format(time.RFC3339)
+	}
+
+	consecutive := nt.Spec.Consecutive
+	if consecutive < 1 {
+		consecutive = 1
+	}
+	if nt.Status.Pending < consecutive {
+		return false
+	}
+	return now.Sub(since) >= time.Duration(nt.Spec.For)*time.Second
+}
+
+// selectField returns the field selected by FieldPath.
+func selectField(spec tmaxiov1alpha1.NotificationTriggerSpec, mr tmaxiov1alpha1.MonitorResult, dat []byte) (interface{}, error) {
+	if spec.Source == tmaxiov1alpha1.FieldSourceResult {
+		var err error
+		if dat, err = json.Marshal(mr); err != nil {
+			return nil, err
+		}
+	}
+
+	selector, err := fieldpath.Compile(spec.FieldPathLanguage, spec.FieldPath)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(dat, &doc); err != nil {
+		return nil, err
+	}
+	return selector.Select(doc)
+}
+
+// compareField compares the field with Operand. The delta operators compare
+// it with the last sample kept in the status of the trigger.
+func compareField(nt *tmaxiov1alpha1.NotificationTrigger, v interface{}, now time.Time) (bool, error) {
+	if nt.Spec.Aggregation != nil {
+		return compareAggregate(nt, v, now)
+	}
+	if !evaluator.IsDelta(nt.Spec.Op) {
+		return evaluator.Eval(v, nt.Spec.Op, nt.Spec.Operand)
+	}
+
+	var prev *evaluator.Sample
+	if last := nt.Status.LastSample; last != nil {
+		prev = &evaluator.Sample{}
+		if err := json.Unmarshal([]byte(last.Value), &prev.Value); err != nil {
+			prev = nil
+		} else if prev.Time, err = time.Parse(time.RFC3339, last.Time); err != nil {
+			prev = nil
+		}
+	}
+
+	value, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	nt.Status.LastSample = &tmaxiov1alpha1.TriggerSample{Value: string(value), Time: now.Format(time.RFC3339Nano)}
+
+	return evaluator.EvalDelta(evaluator.Sample{Value: v, Time: now}, prev, nt.Spec.Op, nt.Spec.Operand)
+}
+
+// compareAggregate adds the field to the window of samples in the status of
+// the trigger, and compares the aggregate of the window with Operand. It
+// doesn't match until the window holds the number of samples, or spans the
+// duration for a window of duration only. The newest sample older than the
+// duration is kept in front of the window to tell that it spans the duration.
+func compareAggregate(nt *tmaxiov1alpha1.NotificationTrigger, v interface{}, now time.Time) (bool, error) {
+	agg := nt.Spec.Aggregation
+	f, ok := evaluator.ToNumber(v)
+	if !ok {
+		return false, fmt.Errorf("value %v is not a number", v)
+	}
+	nt.Status.Samples = append(nt.Status.Samples, tmaxiov1alpha1.TriggerSample{
+		Value: strconv.FormatFloat(f, 'f', -1, 64),
+		Time:  now.Format(time.RFC3339Nano),
+	})
+
+	var boundary *tmaxiov1alpha1.TriggerSample
+	window := []tmaxiov1alpha1.TriggerSample{}
+	for i, s := range nt.Status.Samples {
+		t, err := time.Parse(time.RFC3339Nano, s.Time)
+		if err != nil {
+			continue
+		}
+		if agg.Duration > 0 && now.Sub(t) > time.Duration(agg.Duration)*time.Second {
+			boundary = &nt.Status.Samples[i]
+			continue
+		}
+		window = append(window, s)
+	}
+	limit := tmaxiov1alpha1.SampleLimit
+	if agg.Samples > 0 && agg.Samples < limit {
+		limit = agg.Samples
+	}
+	if len(window) > limit {
+		boundary = &window[len(window)-limit-1]
+		window = window[len(window)-limit:]
+	}
+	if boundary != nil && agg.Samples == 0 {
+		nt.Status.Samples = append([]tmaxiov1alpha1.TriggerSample{*boundary}, window...)
+	} else {
+		nt.Status.Samples = window
+	}
+
+	if agg.Samples > 0 && len(window) < agg.Samples {
+		return false, nil
+	}
+	if agg.Samples == 0 && boundary == nil {
+		return false, nil
+	}
+	values := make([]float64, 0, len(window))
+	for _, s := range window {
+		if f, err := strconv.ParseFloat(s.Value, 64); err == nil {
+			values = append(values, f)
+		}
+	}
+	result, err := evaluator.Aggregate(agg.Function, values, agg.Percentile)
+	if err != nil {
+		return false, err
+	}
+	return evaluator.Eval(result, nt.Spec.Op, nt.Spec.Operand)
+}
+
+// conditions caches the compiled condition of each trigger by namespace/name.
+var conditions = evaluator.NewConditionCache()
+
+// evalCondition evaluates the CEL condition of the trigger key. body and
+// previous are null when they aren't valid JSON.
+func evalCondition(key, expr string, mr tmaxiov1alpha1.MonitorResult, dat, prev []byte) (bool, error) {
+	cond, err := conditions.Get(key, expr)
+	if err != nil {
+		return false, err
+	}
+
+	var body, previous interface{}
+	_ = json.Unmarshal(dat, &body)
+	_ = json.Unmarshal(prev, &previous)
+	result := map[string]interface{}{}
+	raw, err := json.Marshal(mr)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return false, err
+	}
+
+	return cond.Eval(map[string]interface{}{
+		evaluator.VarBody:     body,
+		evaluator.VarResult:   result,
+		evaluator.VarPrevious: previous,
+	})
+}
+
+func sendNotification(o tmaxiov1alpha1.Notification, state notification.State) error {
+	if o.Status.EndPoint == "" {
+		return fmt.Errorf("notification's endpoint not prepared")
+	}
+
+	body, err := json.Marshal(notification.Request{State: state})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", o.Status.EndPoint, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", o.Status.ApiKey)
+
+	if _, err = http.DefaultClient.Do(req); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tmaxiov1alpha1 "github.com/tmax-cloud/alarm-operator/api/v1alpha1"
+	"github.com/tmax-cloud/alarm-operator/pkg/notification"
+)
+
+var testNow = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func ago(seconds int) string {
+	return testNow.Add(-time.Duration(seconds) * time.Second).Format(time.RFC3339)
+}
+
+func TestHolds(t *testing.T) {
+	tests := []struct {
+		name         string
+		consecutive  int
+		forSeconds   int
+		pending      int
+		pendingSince string
+		want         bool
+		wantPending  int
+	}{
+		{"default fires on first match", 0, 0, 0, "", true, 1},
+		{"waits for consecutive", 3, 0, 1, ago(10), false, 2},
+		{"reaches consecutive", 3, 0, 2, ago(20), true, 3},
+		{"waits for duration", 0, 60, 2, ago(30), false, 3},
+		{"reaches duration", 0, 60, 5, ago(60), true, 6},
+		{"needs both", 2, 60, 4, ago(30), false, 5},
+		{"first match starts duration", 0, 60, 0, "", false, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nt := &tmaxiov1alpha1.NotificationTrigger{}
+			nt.Spec.Consecutive = tt.consecutive
+			nt.Spec.For = tt.forSeconds
+			nt.Status.Pending = tt.pending
+			nt.Status.PendingSince = tt.pendingSince
+
+			if got := holds(nt, testNow); got != tt.want {
+				t.Errorf("holds() = %v, want %v", got, tt.want)
+			}
+			if nt.Status.Pending != tt.wantPending {
+				t.Errorf("pending = %d, want %d", nt.Status.Pending, tt.wantPending)
+			}
+			if nt.Status.PendingSince == "" {
+				t.Errorf("pendingSince is not set")
+			}
+		})
+	}
+}
+
+func TestSuppressed(t *testing.T) {
+	tests := []struct {
+		name           string
+		repeatInterval int
+		cooldown       int
+		lastSent       string
+		firing         bool
+		want           time.Duration
+	}{
+		{"never sent", 300, 600, "", true, 0},
+		{"no limits", 0, 0, ago(10), true, -10 * time.Second},
+		{"repeat interval while firing", 300, 0, ago(100), true, 200 * time.Second},
+		{"repeat interval passed", 300, 0, ago(300), true, 0},
+		{"repeat interval ignored for new firing", 300, 0, ago(100), false, -100 * time.Second},
+		{"cooldown for new firing", 0, 600, ago(100), false, 500 * time.Second},
+		{"longer cooldown while firing", 300, 600, ago(100), true, 500 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nt := &tmaxiov1alpha1.NotificationTrigger{}
+			nt.Spec.RepeatInterval = tt.repeatInterval
+			nt.Spec.Cooldown = tt.cooldown
+			nt.Status.LastSent = tt.lastSent
+
+			if got := suppressed(nt, tt.firing, testNow); got != tt.want {
+				t.Errorf("suppressed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSentSince(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastSent string
+		since    string
+		want     bool
+	}{
+		{"never sent", "", ago(10), false},
+		{"sent after", ago(5), ago(10), true},
+		{"sent at", ago(10), ago(10), true},
+		{"sent before", ago(20), ago(10), false},
+		{"invalid since", ago(5), "", false},
+		{"other time zone", testNow.In(time.FixedZone("KST", 9*3600)).Format(time.RFC3339), ago(10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sentSince(tt.lastSent, tt.since); got != tt.want {
+				t.Errorf("sentSince(%s, %s) = %v, want %v", tt.lastSent, tt.since, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	evalErr := fmt.Errorf("unexpected end of JSON input")
+
+	tests := []struct {
+		name        string
+		state       tmaxiov1alpha1.TriggerState
+		consecutive int
+		pending     int
+		lastSent    string
+		firingSince string
+		matched     bool
+		err         error
+		wantState   tmaxiov1alpha1.TriggerState
+		wantSend    notification.State
+		wantPending int
+	}{
+		{"inactive stays", "", 0, 0, "", "", false, nil, "", "", 0},
+		{"inactive to pending", tmaxiov1alpha1.TriggerStateInactive, 3, 0, "", "", true, nil, tmaxiov1alpha1.TriggerStatePending, "", 1},
+		{"inactive to firing", tmaxiov1alpha1.TriggerStateInactive, 0, 0, "", "", true, nil, tmaxiov1alpha1.TriggerStateFiring, notification.StateFiring, 1},
+		{"pending to firing", tmaxiov1alpha1.TriggerStatePending, 3, 2, "", "", true, nil, tmaxiov1alpha1.TriggerStateFiring, notification.StateFiring, 3},
+		{"pending to inactive", tmaxiov1alpha1.TriggerStatePending, 3, 2, "", "", false, nil, tmaxiov1alpha1.TriggerStateInactive, "", 0},
+		{"firing keeps firing", tmaxiov1alpha1.TriggerStateFiring, 0, 5, ago(10), ago(60), true, nil, tmaxiov1alpha1.TriggerStateFiring, notification.StateFiring, 6},
+		{"firing to resolved", tmaxiov1alpha1.TriggerStateFiring, 0, 5, ago(10), ago(60), false, nil, tmaxiov1alpha1.TriggerStateResolved, notification.StateResolved, 0},
+		{"resolved without notification", tmaxiov1alpha1.TriggerStateFiring, 0, 5, ago(120), ago(60), false, nil, tmaxiov1alpha1.TriggerStateResolved, "", 0},
+		{"resolved to firing", tmaxiov1alpha1.TriggerStateResolved, 0, 0, ago(120), "", true, nil, tmaxiov1alpha1.TriggerStateFiring, notification.StateFiring, 1},
+		{"resolved stays", tmaxiov1alpha1.TriggerStateResolved, 0, 0, ago(120), "", false, nil, tmaxiov1alpha1.TriggerStateResolved, "", 0},
+		{"error keeps firing", tmaxiov1alpha1.TriggerStateFiring, 0, 5, ago(10), ago(60), false, evalErr, tmaxiov1alpha1.TriggerStateFiring, "", 5},
+		{"error keeps pending", tmaxiov1alpha1.TriggerStatePending, 3, 2, "", "", false, evalErr, tmaxiov1alpha1.TriggerStatePending, "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nt := &tmaxiov1alpha1.NotificationTrigger{}
+			nt.Spec.Consecutive = tt.consecutive
+			nt.Status.State = tt.state
+			nt.Status.Pending = tt.pending
+			if tt.pending > 0 {
+				nt.Status.PendingSince = ago(600)
+			}
+			nt.Status.LastSent = tt.lastSent
+			nt.Status.FiringSince = tt.firingSince
+
+			result, send := advance(nt, tt.matched, tt.err, testNow)
+			if nt.Status.State != tt.wantState {
+				t.Errorf("state = %s, want %s", nt.Status.State, tt.wantState)
+			}
+			if result.State != tt.wantState {
+				t.Errorf("result state = %s, want %s", result.State, tt.wantState)
+			}
+			if send != tt.wantSend {
+				t.Errorf("send = %q, want %q", send, tt.wantSend)
+			}
+			if nt.Status.Pending != tt.wantPending {
+				t.Errorf("pending = %d, want %d", nt.Status.Pending, tt.wantPending)
+			}
+			if tt.err != nil && result.Message != "failed to evaluate condition: "+tt.err.Error() {
+				t.Errorf("message = %q", result.Message)
+			}
+		})
+	}
+}
+
+func TestCompareAggregate(t *testing.T) {
+	samples := func(values ...string) []tmaxiov1alpha1.TriggerSample {
+		// one sample every 60 seconds, the last one 60 seconds ago
+		ret := []tmaxiov1alpha1.TriggerSample{}
+		for i, v := range values {
+			at := testNow.Add(-time.Duration(len(values)-i) * time.Minute)
+			ret = append(ret, tmaxiov1alpha1.TriggerSample{Value: v, Time: at.Format(time.RFC3339Nano)})
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name        string
+		agg         tmaxiov1alpha1.TriggerAggregation
+		samples     []tmaxiov1alpha1.TriggerSample
+		value       interface{}
+		operand     string
+		want        bool
+		wantErr     bool
+		wantSamples int
+	}{
+		{"duration first sample", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Duration: 300}, nil, float64(1000), "800", false, false, 1},
+		{"duration not spanned", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Duration: 300}, samples("900", "900", "900"), float64(900), "800", false, false, 4},
+		{"duration spanned", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Duration: 300}, samples("100", "900", "900", "900", "900", "900"), float64(900), "800", true, false, 7},
+		{"boundary not aggregated", tmaxiov1alpha1.TriggerAggregation{Function: "max", Duration: 300}, samples("5000", "100", "100", "100", "100", "100"), float64(100), "800", false, false, 7},
+		{"old samples dropped", tmaxiov1alpha1.TriggerAggregation{Function: "count", Duration: 120}, samples("1", "1", "1", "1", "1"), float64(1), "2", true, false, 4},
+		{"samples not enough", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Samples: 3}, samples("900"), float64(900), "800", false, false, 2},
+		{"samples enough", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Samples: 3}, samples("100", "900", "900"), float64(900), "800", true, false, 3},
+		{"percentile", tmaxiov1alpha1.TriggerAggregation{Function: "percentile", Percentile: 50, Samples: 3}, samples("1", "3"), "2", "1.5", true, false, 3},
+		{"not a number", tmaxiov1alpha1.TriggerAggregation{Function: "avg", Samples: 3}, samples("1", "2"), "slow", "1", false, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agg := tt.agg
+			nt := &tmaxiov1alpha1.NotificationTrigger{}
+			nt.Spec.Aggregation = &agg
+			nt.Spec.Op = "gt"
+			nt.Spec.Operand = tt.operand
+			nt.Status.Samples = tt.samples
+
+			got, err := compareAggregate(nt, tt.value, testNow)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("compareAggregate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("compareAggregate() = %t, want %t", got, tt.want)
+			}
+			if len(nt.Status.Samples) != tt.wantSamples {
+				t.Errorf("samples = %d, want %d", len(nt.Status.Samples), tt.wantSamples)
+			}
+		})
+	}
+}
@@ -6,16 +6,23 @@ require (
 	github.com/Jeffail/gabs/v2 v2.6.0
 	github.com/go-logr/logr v0.2.0
 	github.com/go-redis/redis/v7 v7.4.0
+	github.com/golang/protobuf v1.4.3
+	github.com/google/cel-go v0.6.0
 	github.com/gorilla/mux v1.8.0
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/onsi/ginkgo v1.12.1
 	github.com/onsi/gomega v1.10.1
+	github.com/prometheus/client_model v0.2.0
+	github.com/prometheus/common v0.4.1
 	go.uber.org/zap v1.10.0
+	google.golang.org/grpc v1.27.1
 	gopkg.in/alexcesaro/quotedprintable.v3 v3.0.0-20150716171945-2caba252f4dc // indirect
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
 	k8s.io/api v0.20.0
 	k8s.io/apimachinery v0.20.0
 	k8s.io/client-go v0.18.6
 	sigs.k8s.io/controller-runtime v0.6.2
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Aggregate reduces the values by the function: avg, min, max, sum, count or
+// percentile. The percentile is the nearest-rank p-th percentile.
+func Aggregate(function string, values []float64, p int) (float64, error) {
+	if function == "count" {
+		return float64(len(values)), nil
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no samples to aggregate")
+	}
+
+	switch function {
+	case "avg", "sum":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		if function == "sum" {
+			return sum, nil
+		}
+		return sum / float64(len(values)), nil
+	case "min", "max":
+		result := values[0]
+		for _, v := range values[1:] {
+			if (function == "min" && v < result) || (function == "max" && v > result) {
+				result = v
+			}
+		}
+		return result, nil
+	case "percentile":
+		if p < 1 || p > 100 {
+			return 0, fmt.Errorf("percentile %d out of range 1-100", p)
+		}
+		sorted := append([]float64{}, values...)
+		sort.Float64s(sorted)
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+		return sorted[rank-1], nil
+	}
+	return 0, fmt.Errorf("unknown aggregation function: %s", function)
+}
+
+// ToNumber converts numbers and numeric strings to float64.
+func ToNumber(value interface{}) (float64, bool) {
+	return number(value)
+}
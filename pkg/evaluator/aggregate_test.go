@@ -0,0 +1,46 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	values := []float64{300, 100, 900, 500, 200, 1200, 400, 700, 800, 600}
+
+	tests := []struct {
+		name     string
+		function string
+		values   []float64
+		p        int
+		want     float64
+		wantErr  bool
+	}{
+		{"avg", "avg", values, 0, 570, false},
+		{"sum", "sum", values, 0, 5700, false},
+		{"min", "min", values, 0, 100, false},
+		{"max", "max", values, 0, 1200, false},
+		{"count", "count", values, 0, 10, false},
+		{"count empty", "count", nil, 0, 0, false},
+		{"p50", "percentile", values, 50, 500, false},
+		{"p95", "percentile", values, 95, 1200, false},
+		{"p90", "percentile", values, 90, 900, false},
+		{"p100", "percentile", values, 100, 1200, false},
+		{"p1", "percentile", values, 1, 100, false},
+		{"single", "percentile", []float64{42}, 95, 42, false},
+		{"percentile out of range", "percentile", values, 0, 0, true},
+		{"empty", "avg", nil, 0, 0, true},
+		{"unknown", "median", values, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Aggregate(tt.function, tt.values, tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Aggregate(%s) error = %v, wantErr %v", tt.function, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Aggregate(%s) = %v, want %v", tt.function, got, tt.want)
+			}
+		})
+	}
+}
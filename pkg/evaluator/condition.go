@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+)
+
+// Variables of a condition expression.
+const (
+	// VarBody is the parsed response of the monitor.
+	VarBody = "body"
+	// VarResult is the MonitorResult such as status, latencyMs and statusCode.
+	VarResult = "result"
+	// VarPrevious is the parsed response of the previous run, null if not available.
+	VarPrevious = "previous"
+)
+
+var (
+	celEnv     *cel.Env
+	celEnvErr  error
+	celEnvOnce sync.Once
+)
+
+// Condition is a compiled CEL expression evaluating to bool.
+type Condition struct {
+	program cel.Program
+}
+
+func env() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(cel.Declarations(
+			decls.NewVar(VarBody, decls.Dyn),
+			decls.NewVar(VarResult, decls.NewMapType(decls.String, decls.Dyn)),
+			decls.NewVar(VarPrevious, decls.Dyn),
+		))
+	})
+	return celEnv, celEnvErr
+}
+
+// CompileCondition parses and type-checks the expression.
+func CompileCondition(expr string) (*Condition, error) {
+	e, err := env()
+	if err != nil {
+		return nil, err
+	}
+	ast, iss := e.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+		return nil, fmt.Errorf("condition must evaluate to bool, not %v", ast.ResultType())
+	}
+	program, err := e.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Condition{program: program}, nil
+}
+
+// ConditionCache keeps the compiled condition of each owner, so it holds at
+// most one program per owner and a changed expression replaces the old one.
+type ConditionCache struct {
+	mutex      sync.Mutex
+	conditions map[string]cachedCondition
+}
+
+type cachedCondition struct {
+	expr      string
+	condition *Condition
+}
+
+func NewConditionCache() *ConditionCache {
+	return &ConditionCache{conditions: make(map[string]cachedCondition)}
+}
+
+// Get returns the compiled condition of key, compiling expr if key has none
+// or has another expression.
+func (c *ConditionCache) Get(key, expr string) (*Condition, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if cached, ok := c.conditions[key]; ok && cached.expr == expr {
+		return cached.condition, nil
+	}
+	cond, err := CompileCondition(expr)
+	if err != nil {
+		delete(c.conditions, key)
+		return nil, err
+	}
+	c.conditions[key] = cachedCondition{expr: expr, condition: cond}
+	return cond, nil
+}
+
+// Remove drops the condition of key.
+func (c *ConditionCache) Remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.conditions, key)
+}
+
+// Eval evaluates the condition with the variables decoded from JSON.
+func (c *Condition) Eval(vars map[string]interface{}) (bool, error) {
+	out, _, err := c.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition evaluated to %v, not bool", out.Value())
+	}
+	return matched, nil
+}
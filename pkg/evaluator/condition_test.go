@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"testing"
+)
+
+func TestCondition(t *testing.T) {
+	vars := map[string]interface{}{
+		VarBody:     map[string]interface{}{"error_rate": 0.07, "requests": float64(250), "version": "v2"},
+		VarResult:   map[string]interface{}{"status": "Success", "latencyMs": float64(120)},
+		VarPrevious: map[string]interface{}{"version": "v1"},
+	}
+
+	tests := []struct {
+		name       string
+		expr       string
+		want       bool
+		wantErr    bool
+		compileErr bool
+	}{
+		{"and", "body.error_rate > 0.05 && body.requests > 100.0", true, false, false},
+		{"result", `result.status == "Success" && result.latencyMs < 100.0`, false, false, false},
+		{"previous", "body.version != previous.version", true, false, false},
+		{"has", "has(body.error_rate)", true, false, false},
+		{"missing field", "body.missing > 1.0", false, true, false},
+		{"syntax error", "body.error_rate >", false, false, true},
+		{"not bool", "body.requests + 1.0", false, false, true},
+		{"undeclared", "monitor.status == 1", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := CompileCondition(tt.expr)
+			if (err != nil) != tt.compileErr {
+				t.Fatalf("CompileCondition(%s) error = %v, compileErr %v", tt.expr, err, tt.compileErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := c.Eval(vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval(%s) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%s) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionCache(t *testing.T) {
+	c := NewConditionCache()
+	first, err := c.Get("default/t", "result.status == 'Fail'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Get("default/t", "result.status == 'Fail'"); again != first {
+		t.Error("same expression compiled again")
+	}
+
+	changed, err := c.Get("default/t", "result.latencyMs > 100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == first || len(c.conditions) != 1 {
+		t.Errorf("changed expression not replaced, %d conditions cached", len(c.conditions))
+	}
+
+	if _, err := c.Get("default/t", "result.status =="); err == nil {
+		t.Error("invalid expression compiled")
+	}
+	if len(c.conditions) != 0 {
+		t.Errorf("invalid expression left %d conditions cached", len(c.conditions))
+	}
+
+	_, _ = c.Get("default/a", "true")
+	_, _ = c.Get("default/b", "true")
+	c.Remove("default/a")
+	if _, ok := c.conditions["default/a"]; ok || len(c.conditions) != 1 {
+		t.Errorf("Remove() left %v", c.conditions)
+	}
+}
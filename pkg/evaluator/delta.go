@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sample is a value selected at a time.
+type Sample struct {
+	Value interface{}
+	Time  time.Time
+}
+
+// IsDelta reports whether op compares a sample with the previous one.
+func IsDelta(op string) bool {
+	switch op {
+	case "changed", "increasedBy", "decreasedBy", "ratePerSecond":
+		return true
+	}
+	return false
+}
+
+// EvalDelta compares the sample with the previous one, and doesn't match
+// without the previous sample. changed matches a value different from the
+// previous one. increasedBy and decreasedBy match a change of operand or
+// more, either absolute ("10") or relative to the previous value ("50%").
+// ratePerSecond compares the change per second by operand (ex: "> 100"),
+// with > for a bare number.
+func EvalDelta(cur Sample, prev *Sample, op, operand string) (bool, error) {
+	if prev == nil {
+		return false, nil
+	}
+	if op == "changed" {
+		return !reflect.DeepEqual(cur.Value, prev.Value), nil
+	}
+
+	v, ok := number(cur.Value)
+	if !ok {
+		return false, fmt.Errorf("value %v is not a number", cur.Value)
+	}
+	p, ok := number(prev.Value)
+	if !ok {
+		return false, fmt.Errorf("previous value %v is not a number", prev.Value)
+	}
+
+	switch op {
+	case "increasedBy", "decreasedBy":
+		delta := v - p
+		if op == "decreasedBy" {
+			delta = -delta
+		}
+		if strings.HasSuffix(operand, "%") {
+			percent, err := strconv.ParseFloat(strings.TrimSuffix(operand, "%"), 64)
+			if err != nil {
+				return false, fmt.Errorf("operand %q is not a percentage", operand)
+			}
+			if p == 0 {
+				return delta > 0, nil
+			}
+			return delta/abs(p)*100 >= percent, nil
+		}
+		threshold, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return false, fmt.Errorf("operand %q is not a number", operand)
+		}
+		return delta >= threshold, nil
+	case "ratePerSecond":
+		elapsed := cur.Time.Sub(prev.Time).Seconds()
+		if elapsed <= 0 {
+			return false, nil
+		}
+		cmp, threshold := splitRateOperand(operand)
+		return Eval((v-p)/elapsed, cmp, threshold)
+	}
+	return false, fmt.Errorf("unknown operator: %s", op)
+}
+
+// validateDelta checks the operand of the delta operator.
+func validateDelta(op, operand string) error {
+	switch op {
+	case "increasedBy", "decreasedBy":
+		if _, err := strconv.ParseFloat(strings.TrimSuffix(operand, "%"), 64); err != nil {
+			return fmt.Errorf("operand %q is not a number or percentage", operand)
+		}
+	case "ratePerSecond":
+		cmp, threshold := splitRateOperand(operand)
+		if _, err := strconv.ParseFloat(threshold, 64); err != nil {
+			return fmt.Errorf("operand %q is not a number", operand)
+		}
+		return Validate(cmp, threshold)
+	}
+	return nil
+}
+
+func splitRateOperand(operand string) (string, string) {
+	fields := strings.Fields(operand)
+	if len(fields) == 2 {
+		return fields[0], fields[1]
+	}
+	return ">", strings.TrimSpace(operand)
+}
+
+// number converts numbers and numeric strings to float64.
+func number(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return toFloat(value)
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvalDelta(t *testing.T) {
+	now := time.Now()
+	at := func(v interface{}, secondsAgo int) *Sample {
+		return &Sample{Value: v, Time: now.Add(-time.Duration(secondsAgo) * time.Second)}
+	}
+
+	tests := []struct {
+		name    string
+		cur     *Sample
+		prev    *Sample
+		op      string
+		operand string
+		want    bool
+		wantErr bool
+	}{
+		{"no previous", at(float64(1), 0), nil, "changed", "", false, false},
+		{"changed string", at("v2", 0), at("v1", 10), "changed", "", true, false},
+		{"not changed", at("v1", 0), at("v1", 10), "changed", "", false, false},
+		{"changed number", at(float64(2), 0), at(float64(1), 10), "changed", "", true, false},
+		{"changed to null", at(nil, 0), at(float64(1), 10), "changed", "", true, false},
+		{"changed list", at([]interface{}{"a"}, 0), at([]interface{}{"a"}, 10), "changed", "", false, false},
+
+		{"increasedBy", at(float64(120), 0), at(float64(100), 10), "increasedBy", "20", true, false},
+		{"not increasedBy", at(float64(110), 0), at(float64(100), 10), "increasedBy", "20", false, false},
+		{"increasedBy decrease", at(float64(80), 0), at(float64(100), 10), "increasedBy", "10", false, false},
+		{"increasedBy percent", at(float64(150), 0), at(float64(100), 10), "increasedBy", "50%", true, false},
+		{"increasedBy percent from zero", at(float64(1), 0), at(float64(0), 10), "increasedBy", "50%", true, false},
+		{"increasedBy numeric string", at("12", 0), at("10", 10), "increasedBy", "2", true, false},
+		{"decreasedBy", at(float64(80), 0), at(float64(100), 10), "decreasedBy", "20", true, false},
+		{"decreasedBy percent", at(float64(90), 0), at(float64(100), 10), "decreasedBy", "20%", false, false},
+		{"increasedBy not number", at("up", 0), at("down", 10), "increasedBy", "1", false, true},
+		{"increasedBy invalid operand", at(float64(1), 0), at(float64(0), 10), "increasedBy", "one", false, true},
+
+		{"ratePerSecond", at(float64(2000), 0), at(float64(1000), 5), "ratePerSecond", "> 100", true, false},
+		{"ratePerSecond bare", at(float64(2000), 0), at(float64(1000), 5), "ratePerSecond", "300", false, false},
+		{"ratePerSecond word operator", at(float64(1000), 0), at(float64(2000), 10), "ratePerSecond", "lt 0", true, false},
+		{"ratePerSecond same time", at(float64(2), 0), at(float64(1), 0), "ratePerSecond", "> 0", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalDelta(*tt.cur, tt.prev, tt.op, tt.operand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvalDelta(%s, %s) error = %v, wantErr %v", tt.op, tt.operand, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EvalDelta(%s, %s) = %v, want %v", tt.op, tt.operand, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Eval reports whether value, selected from a document decoded from JSON,
+// satisfies the operator against operand.
+//
+// Numbers are compared as float64 and bools as bools. Strings are ordered
+// numerically when both sides are numbers, as durations when both sides are
+// durations (ex: "1m30s" gt "60s") and lexically otherwise, while eq and ne
+// compare strings as they are. A missing value equals "null".
+func Eval(value interface{}, op, operand string) (bool, error) {
+	switch op {
+	case "exists":
+		return value != nil, nil
+	case "notExists":
+		return value == nil, nil
+	case "eq", "==":
+		return equal(value, operand), nil
+	case "ne", "!=":
+		return !equal(value, operand), nil
+	case "contains":
+		return contains(value, operand), nil
+	case "matches":
+		re, err := regexp.Compile(operand)
+		if err != nil {
+			return false, err
+		}
+		if value == nil {
+			return false, nil
+		}
+		return re.MatchString(toString(value)), nil
+	case "in":
+		for _, o := range strings.Split(operand, ",") {
+			if equal(value, strings.TrimSpace(o)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "gt", ">", "gte", ">=", "lt", "<", "lte", "<=":
+		c, err := compare(value, operand)
+		if err != nil {
+			return false, err
+		}
+		switch op {
+		case "gt", ">":
+			return c > 0, nil
+		case "gte", ">=":
+			return c >= 0, nil
+		case "lt", "<":
+			return c < 0, nil
+		default:
+			return c <= 0, nil
+		}
+	}
+	return false, fmt.Errorf("unknown operator: %s", op)
+}
+
+// Validate checks that op is a known operator and operand is valid for it.
+func Validate(op, operand string) error {
+	switch op {
+	case "exists", "notExists", "eq", "==", "ne", "!=", "contains", "in",
+		"gt", ">", "gte", ">=", "lt", "<", "lte", "<=":
+		return nil
+	case "matches":
+		_, err := regexp.Compile(operand)
+		return err
+	case "changed", "increasedBy", "decreasedBy", "ratePerSecond":
+		return validateDelta(op, operand)
+	}
+	return fmt.Errorf("unknown operator: %s", op)
+}
+
+func equal(value interface{}, operand string) bool {
+	switch v := value.(type) {
+	case nil:
+		return operand == "null"
+	case bool:
+		b, err := strconv.ParseBool(operand)
+		return err == nil && b == v
+	case string:
+		return v == operand
+	case []interface{}, map[string]interface{}:
+		return toString(v) == operand
+	}
+	if f, ok := toFloat(value); ok {
+		o, err := strconv.ParseFloat(operand, 64)
+		return err == nil && f == o
+	}
+	return toString(value) == operand
+}
+
+// compare returns -1, 0 or 1 as value is less than, equal to or greater than
+// operand.
+func compare(value interface{}, operand string) (int, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, fmt.Errorf("value doesn't exist")
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			if o, err := strconv.ParseFloat(operand, 64); err == nil {
+				return compareFloat(f, o), nil
+			}
+		}
+		if d, err := time.ParseDuration(v); err == nil {
+			if o, err := time.ParseDuration(operand); err == nil {
+				return compareFloat(float64(d), float64(o)), nil
+			}
+		}
+		return strings.Compare(v, operand), nil
+	}
+	if f, ok := toFloat(value); ok {
+		o, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return 0, fmt.Errorf("operand %q is not a number", operand)
+		}
+		return compareFloat(f, o), nil
+	}
+	return 0, fmt.Errorf("value of %T can't be ordered", value)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// contains reports whether a string contains operand, a list has an element
+// equal to operand or a map has operand as a key.
+func contains(value interface{}, operand string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(v, operand)
+	case []interface{}:
+		for _, e := range v {
+			if equal(e, operand) {
+				return true
+			}
+		}
+		return false
+	case map[string]interface{}:
+		_, ok := v[operand]
+		return ok
+	}
+	return strings.Contains(toString(value), operand)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		dat, err := json.Marshal(v)
+		if err == nil {
+			return string(dat)
+		}
+	}
+	if f, ok := toFloat(value); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,133 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	list := []interface{}{"a", float64(2), nil}
+	object := map[string]interface{}{"status": "UP"}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		op      string
+		operand string
+		want    bool
+		wantErr bool
+	}{
+		{"float gt", float64(0.7), "gt", "0.5", true, false},
+		{"float gt symbol", float64(0.7), ">", "0.5", true, false},
+		{"float not gt", float64(0.5), "gt", "0.5", false, false},
+		{"float gte", float64(0.5), "gte", "0.5", true, false},
+		{"float gte symbol", float64(0.5), ">=", "0.5", true, false},
+		{"float lt", float64(0.3), "lt", "0.5", true, false},
+		{"float lt symbol", float64(0.3), "<", "0.5", true, false},
+		{"float not lt", float64(0.7), "<", "0.5", false, false},
+		{"float lte", float64(0.5), "lte", "0.5", true, false},
+		{"float lte symbol", float64(0.6), "<=", "0.5", false, false},
+		{"fraction operand", float64(0), "lt", "0.5", true, false},
+		{"int", int(3), "gt", "2", true, false},
+		{"int64 eq", int64(3), "eq", "3", true, false},
+		{"json number", json.Number("1.5"), "gt", "1", true, false},
+		{"float eq", float64(1), "eq", "1.0", true, false},
+		{"float eq symbol", float64(1), "==", "1", true, false},
+		{"float ne", float64(1), "ne", "2", true, false},
+		{"float ne symbol", float64(1), "!=", "1", false, false},
+		{"float eq non number", float64(1), "eq", "one", false, false},
+		{"float gt non number", float64(1), "gt", "one", false, true},
+
+		{"bool eq", true, "eq", "true", true, false},
+		{"bool eq false", false, "eq", "false", true, false},
+		{"bool ne", false, "ne", "true", true, false},
+		{"bool eq non bool", true, "eq", "yes", false, false},
+		{"bool gt", true, "gt", "false", false, true},
+
+		{"string eq", "UP", "eq", "UP", true, false},
+		{"string ne", "UP", "ne", "DOWN", true, false},
+		{"string eq exact", "1.10", "eq", "1.1", false, false},
+		{"string lexical", "b", "gt", "a", true, false},
+		{"numeric string", "10", "gt", "9", true, false},
+		{"numeric string lt", "0.25", "lt", "0.5", true, false},
+		{"duration string", "1m30s", "gt", "60s", true, false},
+		{"duration string lt", "300ms", "lt", "1s", true, false},
+
+		{"null eq", nil, "eq", "null", true, false},
+		{"null ne", nil, "ne", "null", false, false},
+		{"null eq value", nil, "eq", "0", false, false},
+		{"null gt", nil, "gt", "0", false, true},
+		{"null contains", nil, "contains", "a", false, false},
+		{"null matches", nil, "matches", ".*", false, false},
+		{"null in", nil, "in", "a, null", true, false},
+
+		{"exists", float64(0), "exists", "", true, false},
+		{"exists empty string", "", "exists", "", true, false},
+		{"exists null", nil, "exists", "", false, false},
+		{"notExists", nil, "notExists", "", true, false},
+		{"notExists value", false, "notExists", "", false, false},
+
+		{"string contains", "connection refused", "contains", "refused", true, false},
+		{"string not contains", "ok", "contains", "refused", false, false},
+		{"list contains string", list, "contains", "a", true, false},
+		{"list contains number", list, "contains", "2", true, false},
+		{"list contains null", list, "contains", "null", true, false},
+		{"list not contains", list, "contains", "b", false, false},
+		{"map contains key", object, "contains", "status", true, false},
+		{"map not contains key", object, "contains", "UP", false, false},
+		{"number contains", float64(1234), "contains", "23", true, false},
+
+		{"matches", "v1.2.3", "matches", `^v1\.`, true, false},
+		{"not matches", "v2.0.0", "matches", `^v1\.`, false, false},
+		{"number matches", float64(503), "matches", `^5\d\d$`, true, false},
+		{"invalid regex", "a", "matches", `(`, false, true},
+
+		{"in", "DOWN", "in", "DOWN,UNKNOWN", true, false},
+		{"in with spaces", "UNKNOWN", "in", "DOWN, UNKNOWN", true, false},
+		{"not in", "UP", "in", "DOWN,UNKNOWN", false, false},
+		{"number in", float64(503), "in", "502,503,504", true, false},
+		{"bool in", true, "in", "true", true, false},
+
+		{"list eq", []interface{}{}, "eq", "[]", true, false},
+		{"list gt", list, "gt", "1", false, true},
+		{"unknown operator", float64(1), "like", "1", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Eval(tt.value, tt.op, tt.operand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Eval(%v, %s, %s) error = %v, wantErr %v", tt.value, tt.op, tt.operand, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%v, %s, %s) = %v, want %v", tt.value, tt.op, tt.operand, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		op      string
+		operand string
+		wantErr bool
+	}{
+		{"gt", "0.5", false},
+		{"<=", "1", false},
+		{"notExists", "", false},
+		{"matches", `^v1\.`, false},
+		{"matches", `(`, true},
+		{"like", "a", true},
+		{"changed", "", false},
+		{"increasedBy", "10%", false},
+		{"decreasedBy", "ten", true},
+		{"ratePerSecond", "> 100", false},
+		{"ratePerSecond", "like 100", true},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(tt.op, tt.operand); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s, %s) error = %v, wantErr %v", tt.op, tt.operand, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package fieldpath
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/jmespath/go-jmespath"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// Gabs is the dotted path of gabs, ex: items.0.status
+	Gabs = "gabs"
+	// JSONPath is the kubectl flavored JSONPath, ex: {.items[?(@.status=="DOWN")].name}
+	JSONPath = "jsonpath"
+	// JMESPath is a JMESPath expression, ex: length(items[?status=='DOWN'])
+	JMESPath = "jmespath"
+)
+
+// Selector selects a value from a document decoded from JSON.
+type Selector interface {
+	Select(doc interface{}) (interface{}, error)
+}
+
+// Compile parses the expression of the language. An empty language is gabs.
+func Compile(language, expr string) (Selector, error) {
+	switch language {
+	case "", Gabs:
+		return gabsSelector(expr), nil
+	case JSONPath:
+		jp := jsonpath.New("fieldPath").AllowMissingKeys(true)
+		if err := jp.Parse(relaxedJSONPath(expr)); err != nil {
+			return nil, err
+		}
+		return &jsonPathSelector{jp: jp}, nil
+	case JMESPath:
+		jp, err := jmespath.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		return jmesPathSelector{jp: jp}, nil
+	}
+	return nil, fmt.Errorf("unknown field path language: %s", language)
+}
+
+type gabsSelector string
+
+func (s gabsSelector) Select(doc interface{}) (interface{}, error) {
+	return gabs.Wrap(doc).Path(string(s)).Data(), nil
+}
+
+type jsonPathSelector struct {
+	jp *jsonpath.JSONPath
+}
+
+// Select returns nil for no match, the value for a single match and a list
+// of the values for multiple matches.
+func (s *jsonPathSelector) Select(doc interface{}) (interface{}, error) {
+	results, err := s.jp.FindResults(doc)
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, v.Interface())
+		}
+	}
+	switch len(values) {
+	case 0:
+		return nil, nil
+	case 1:
+		return values[0], nil
+	}
+	return values, nil
+}
+
+type jmesPathSelector struct {
+	jp *jmespath.JMESPath
+}
+
+func (s jmesPathSelector) Select(doc interface{}) (interface{}, error) {
+	return s.jp.Search(doc)
+}
+
+// relaxedJSONPath adds the braces and leading dot kubectl doesn't require,
+// so items[0].status and $.items[0].status are accepted as well.
+func relaxedJSONPath(expr string) string {
+	if strings.HasPrefix(expr, "{") {
+		return expr
+	}
+	expr = strings.TrimPrefix(expr, "$")
+	if !strings.HasPrefix(expr, ".") {
+		expr = "." + expr
+	}
+	return "{" + expr + "}"
+}
@@ -0,0 +1,94 @@
+package fieldpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRelaxedJSONPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"{.items[0].status}", "{.items[0].status}"},
+		{".items[0].status", "{.items[0].status}"},
+		{"items[0].status", "{.items[0].status}"},
+		{"$.items[0].status", "{.items[0].status}"},
+		{"$", "{.}"},
+	}
+	for _, tt := range tests {
+		if got := relaxedJSONPath(tt.expr); got != tt.want {
+			t.Errorf("relaxedJSONPath(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"items": [
+			{"name": "a", "status": "UP", "latency": 10},
+			{"name": "b", "status": "DOWN", "latency": 20},
+			{"name": "c", "status": "DOWN", "latency": 30}
+		],
+		"total": 3
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		language string
+		expr     string
+		want     interface{}
+		wantErr  bool
+	}{
+		{"gabs default", "", "items.0.status", "UP", false},
+		{"gabs", Gabs, "total", float64(3), false},
+		{"gabs missing", Gabs, "items.0.missing", nil, false},
+		{"jsonpath single", JSONPath, "{.items[1].name}", "b", false},
+		{"jsonpath relaxed", JSONPath, "items[1].name", "b", false},
+		{"jsonpath dollar", JSONPath, "$.total", float64(3), false},
+		{"jsonpath list", JSONPath, `{.items[?(@.status=="DOWN")].name}`, []interface{}{"b", "c"}, false},
+		{"jsonpath filter single", JSONPath, `{.items[?(@.status=="UP")].latency}`, float64(10), false},
+		{"jsonpath no match", JSONPath, `{.items[?(@.status=="UNKNOWN")].name}`, nil, false},
+		{"jsonpath missing key", JSONPath, "{.missing}", nil, false},
+		{"jmespath count", JMESPath, "items[?status=='DOWN'] | length(@)", float64(2), false},
+		{"jmespath length", JMESPath, "length(items[?status=='DOWN'])", float64(2), false},
+		{"jmespath projection", JMESPath, "items[].latency", []interface{}{float64(10), float64(20), float64(30)}, false},
+		{"jmespath missing", JMESPath, "missing", nil, false},
+		{"jmespath type error", JMESPath, "length(total)", nil, true},
+	}
+	for _, tt := range tests {
+		s, err := Compile(tt.language, tt.expr)
+		if err != nil {
+			t.Errorf("%s: Compile(%q) = %v", tt.name, tt.expr, err)
+			continue
+		}
+		got, err := s.Select(doc)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Select() error = %v, wantErr %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Select() = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	tests := []struct {
+		language string
+		expr     string
+	}{
+		{"xpath", "/items"},
+		{JSONPath, "{.items[}"},
+		{JMESPath, "items[?status=="},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.language, tt.expr); err == nil {
+			t.Errorf("Compile(%q, %q) succeeded", tt.language, tt.expr)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package heartbeat
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Status is the last ping of a heartbeat reported by the notifier.
+type Status struct {
+	LastPing string `json:"lastPing,omitempty"`
+}
+
+// ErrRegistered is returned when id is registered with another token.
+var ErrRegistered = errors.New("heartbeat already registered with another token")
+
+type Store interface {
+	// CreateToken saves token unless id already has one and reports whether it was saved.
+	CreateToken(id string, token string) (bool, error)
+	LoadToken(id string) (string, error)
+	DeleteToken(id string) error
+	SavePing(id string, data []byte) error
+	LoadPing(id string) ([]byte, error)
+	DeletePing(id string) error
+}
+
+type Registry struct {
+	ds Store
+}
+
+func NewRegistry(dataSource Store) *Registry {
+	return &Registry{ds: dataSource}
+}
+
+// NewToken generates a random ping token.
+func NewToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// Register sets the ping token of id. Registering the same token again is a
+// no-op, and it fails with ErrRegistered if id already has another token.
+// The token is never handed out by the registry.
+func (r *Registry) Register(id string, token string) error {
+	created, err := r.ds.CreateToken(id, token)
+	if err != nil || created {
+		return err
+	}
+	registered, err := r.ds.LoadToken(id)
+	if err != nil {
+		return err
+	}
+	if registered != token {
+		return ErrRegistered
+	}
+	return nil
+}
+
+// Unregister deletes the token and the last ping of id.
+func (r *Registry) Unregister(id string) error {
+	if err := r.ds.DeletePing(id); err != nil {
+		return err
+	}
+	return r.ds.DeleteToken(id)
+}
+
+// Ping records a ping of id at t if token matches the registered one.
+func (r *Registry) Ping(id string, token string, t time.Time) error {
+	if err := r.verify(id, token); err != nil {
+		return err
+	}
+	return r.ds.SavePing(id, []byte(strconv.FormatInt(t.Unix(), 10)))
+}
+
+func (r *Registry) verify(id string, token string) error {
+	registered, err := r.ds.LoadToken(id)
+	if err != nil {
+		return err
+	}
+	if registered == "" || registered != token {
+		return fmt.Errorf("token not match")
+	}
+	return nil
+}
+
+// LastPing returns the time of the last ping of id. Zero if never pinged.
+func (r *Registry) LastPing(id string) (time.Time, error) {
+	data, err := r.ds.LoadPing(id)
+	if err != nil || len(data) == 0 {
+		return time.Time{}, err
+	}
+	sec, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
@@ -0,0 +1,77 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	tokens map[string]string
+	pings  map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{tokens: map[string]string{}, pings: map[string][]byte{}}
+}
+
+func (s *memoryStore) CreateToken(id string, token string) (bool, error) {
+	if _, ok := s.tokens[id]; ok {
+		return false, nil
+	}
+	s.tokens[id] = token
+	return true, nil
+}
+
+func (s *memoryStore) LoadToken(id string) (string, error) { return s.tokens[id], nil }
+func (s *memoryStore) DeleteToken(id string) error         { delete(s.tokens, id); return nil }
+func (s *memoryStore) SavePing(id string, data []byte) error {
+	s.pings[id] = data
+	return nil
+}
+func (s *memoryStore) LoadPing(id string) ([]byte, error) { return s.pings[id], nil }
+func (s *memoryStore) DeletePing(id string) error         { delete(s.pings, id); return nil }
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(newMemoryStore())
+	now := time.Unix(1600000000, 0)
+
+	token, err := NewToken()
+	if err != nil || len(token) != 32 {
+		t.Fatalf("NewToken() = %q, %v", token, err)
+	}
+	if err := r.Register("job-default", token); err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	if err := r.Register("job-default", token); err != nil {
+		t.Errorf("Register() of the same token = %v", err)
+	}
+	if err := r.Register("job-default", "other"); err != ErrRegistered {
+		t.Errorf("Register() of another token = %v, want ErrRegistered", err)
+	}
+
+	if err := r.Ping("job-default", "wrong", now); err == nil {
+		t.Error("Ping() with wrong token succeeded")
+	}
+	if err := r.Ping("job-default", token, now); err != nil {
+		t.Errorf("Ping() = %v", err)
+	}
+	if last, err := r.LastPing("job-default"); err != nil || !last.Equal(now) {
+		t.Errorf("LastPing() = %v, %v, want %v", last, err, now)
+	}
+
+	if err := r.Unregister("job-default"); err != nil {
+		t.Errorf("Unregister() = %v", err)
+	}
+	if last, err := r.LastPing("job-default"); err != nil || !last.IsZero() {
+		t.Errorf("LastPing() after Unregister() = %v, %v, want zero", last, err)
+	}
+	if err := r.Ping("job-default", token, now); err == nil {
+		t.Error("Ping() after Unregister() succeeded")
+	}
+	if err := r.Register("job-default", "other"); err != nil {
+		t.Errorf("Register() after Unregister() = %v", err)
+	}
+	if err := r.Unregister("never-registered"); err != nil {
+		t.Errorf("Unregister() of unknown id = %v", err)
+	}
+}
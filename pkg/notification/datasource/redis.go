@@ -5,8 +5,10 @@ import (
 )
 
 const (
-	RegistryKey = "noti_reg"
-	QueueKey    = "noti_queue"
+	RegistryKey      = "noti_reg"
+	QueueKey         = "noti_queue"
+	HeartbeatKey     = "heartbeat_reg"
+	HeartbeatPingKey = "heartbeat_ping"
 )
 
 type RedisDataSource struct {
@@ -55,3 +57,44 @@ func (s *RedisDataSource) Pop() ([]byte, error) {
 
 	return []byte(r.Val()), nil
 }
+
+// CreateToken doesn't overwrite the token of id if it exists.
+func (s *RedisDataSource) CreateToken(id string, token string) (bool, error) {
+	return s.client.HSetNX(HeartbeatKey, id, token).Result()
+}
+
+// LoadToken returns empty token if id is not registered.
+func (s *RedisDataSource) LoadToken(id string) (string, error) {
+	r := s.client.HGet(HeartbeatKey, id)
+	if r.Err() == redis.Nil {
+		return "", nil
+	} else if r.Err() != nil {
+		return "", r.Err()
+	}
+
+	return r.Val(), nil
+}
+
+func (s *RedisDataSource) DeleteToken(id string) error {
+	return s.client.HDel(HeartbeatKey, id).Err()
+}
+
+func (s *RedisDataSource) SavePing(id string, data []byte) error {
+	return s.client.HSet(HeartbeatPingKey, id, data).Err()
+}
+
+// LoadPing returns nil if id has never been pinged.
+func (s *RedisDataSource) LoadPing(id string) ([]byte, error) {
+	r := s.client.HGet(HeartbeatPingKey, id)
+	if r.Err() == redis.Nil {
+		return nil, nil
+	} else if r.Err() != nil {
+		return nil, r.Err()
+	}
+
+	return []byte(r.Val()), nil
+}
+
+func (s *RedisDataSource) DeletePing(id string) error {
+	return s.client.HDel(HeartbeatPingKey, id).Err()
+}
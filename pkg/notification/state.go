@@ -0,0 +1,33 @@
+package notification
+
+// State is the state of the alert a notification request is sent for.
+type State string
+
+const (
+	StateFiring   State = "Firing"
+	StateResolved State = "Resolved"
+)
+
+const resolvedPrefix = "[RESOLVED] "
+
+// Request is the optional body of a notification request. An empty body
+// is a firing notification.
+type Request struct {
+	State State `json:"state,omitempty"`
+}
+
+// Resolved returns a copy of the notification telling the alert is resolved.
+func Resolved(noti Notification) Notification {
+	switch n := noti.(type) {
+	case MailNotification:
+		n.Subject = resolvedPrefix + n.Subject
+		return n
+	case WebhookNotification:
+		n.Message = resolvedPrefix + n.Message
+		return n
+	case SlackNotification:
+		n.Text = resolvedPrefix + n.Text
+		return n
+	}
+	return noti
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/tmax-cloud/alarm-operator/pkg/heartbeat"
 	"github.com/tmax-cloud/alarm-operator/pkg/notification"
 )
 
@@ -29,3 +33,66 @@ func (c *Notifier) Register(id, notiType string, noti notification.Notification)
 	endpoint := fmt.Sprintf("%s/internal/notification/%s?type=%s", c.URL, id, notiType)
 	return http.Post(endpoint, "application/json", bytes.NewBuffer(payload))
 }
+
+// PingURL returns the URL the monitored job pings with token.
+func (c *Notifier) PingURL(id, token string) string {
+	return fmt.Sprintf("%s/heartbeat/%s/%s", c.URL, id, token)
+}
+
+// RegisterHeartbeat regist token of heartbeat. It's safe to register the same
+// token again, and heartbeat.ErrRegistered is returned if id has another token.
+func (c *Notifier) RegisterHeartbeat(id, token string) error {
+	resp, err := http.Post(fmt.Sprintf("%s/internal/heartbeat/%s", c.URL, id), "text/plain", strings.NewReader(token))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusConflict:
+		return heartbeat.ErrRegistered
+	}
+	msg, _ := ioutil.ReadAll(resp.Body)
+	return fmt.Errorf("failed to register heartbeat: %s", msg)
+}
+
+// UnregisterHeartbeat removes the heartbeat and its last ping.
+// A heartbeat that is already gone is not an error.
+func (c *Notifier) UnregisterHeartbeat(id string) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/internal/heartbeat/%s", c.URL, id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to unregister heartbeat: %s", resp.Status)
+	}
+	return nil
+}
+
+// LastPing returns the time of the last ping of heartbeat. Zero if never pinged.
+func (c *Notifier) LastPing(id string) (time.Time, error) {
+	resp, err := http.Get(fmt.Sprintf("%s/internal/heartbeat/%s", c.URL, id))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("failed to fetch heartbeat: %s", resp.Status)
+	}
+
+	status := heartbeat.Status{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return time.Time{}, err
+	}
+	if status.LastPing == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, status.LastPing)
+}
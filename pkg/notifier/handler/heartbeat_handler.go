@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tmax-cloud/alarm-operator/pkg/heartbeat"
+	"go.uber.org/zap"
+)
+
+type heartbeatHandler struct {
+	ctx      context.Context
+	registry *heartbeat.Registry
+	logger   *zap.SugaredLogger
+}
+
+// NewHeartbeatRegistryHandler handles registration(POST) of the token in the body,
+// removal(DELETE) and last ping lookup(GET) of heartbeats from the operator.
+func NewHeartbeatRegistryHandler(ctx context.Context, registry *heartbeat.Registry, logger *zap.SugaredLogger) http.Handler {
+	return &heartbeatHandler{
+		ctx:      ctx,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+func (h *heartbeatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	switch r.Method {
+	case http.MethodPost:
+		token, err := ioutil.ReadAll(r.Body)
+		if err != nil || len(token) == 0 {
+			http.Error(w, "token is required", http.StatusBadRequest)
+			return
+		}
+		err = h.registry.Register(id, string(token))
+		if err == heartbeat.ErrRegistered {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		} else if err != nil {
+			msg := fmt.Sprintf("failed to register heartbeat(id: %s): %s", id, err.Error())
+			h.logger.Error(msg)
+			http.Error(w, msg, http.StatusInternalServerError)
+			return
+		}
+		h.logger.Infow("heartbeat registered", "id", id)
+		w.WriteHeader(http.StatusOK)
+		return
+	case http.MethodDelete:
+		if err := h.registry.Unregister(id); err != nil {
+			msg := fmt.Sprintf("failed to unregister heartbeat(id: %s): %s", id, err.Error())
+			h.logger.Error(msg)
+			http.Error(w, msg, http.StatusInternalServerError)
+			return
+		}
+		h.logger.Infow("heartbeat removed", "id", id)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	last, err := h.registry.LastPing(id)
+	if err != nil {
+		msg := fmt.Sprintf("failed to fetch heartbeat(id: %s): %s", id, err.Error())
+		h.logger.Error(msg)
+		http.Error(w, msg, http.StatusInternalServerError)
+		return
+	}
+	status := heartbeat.Status{}
+	if !last.IsZero() {
+		status.LastPing = last.Format(time.RFC3339)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+type pingHandler struct {
+	ctx      context.Context
+	registry *heartbeat.Registry
+	logger   *zap.SugaredLogger
+}
+
+// NewPingHandler records pings sent by the monitored jobs.
+func NewPingHandler(ctx context.Context, registry *heartbeat.Registry, logger *zap.SugaredLogger) http.Handler {
+	return &pingHandler{
+		ctx:      ctx,
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+func (h *pingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.registry.Ping(vars["id"], vars["token"], time.Now()); err != nil {
+		h.logger.Infow("ping rejected", "id", vars["id"], "error", err)
+		http.Error(w, "heartbeat not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"strings"
 
@@ -47,6 +50,10 @@ func (h *notificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	}
 	h.logger.Infow("handler", "Host", r.Host, "extracted", id, "notification", noti)
 
+	if h.requestState(r) == notification.StateResolved {
+		noti = notification.Resolved(noti)
+	}
+
 	err = h.queue.Enqueue(noti)
 	if err != nil {
 		h.logger.Error(err)
@@ -58,6 +65,23 @@ func (h *notificationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	_, _ = w.Write([]byte(fmt.Sprintf("Notification: %s reserved.\n", id)))
 }
 
+// requestState returns the state of the optional {"state": ...} body. Other
+// bodies are accepted and ignored, since callers may send any payload.
+func (h *notificationHandler) requestState(r *http.Request) notification.State {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return ""
+	}
+	var req notification.Request
+	if body, err := ioutil.ReadAll(r.Body); err == nil && len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			h.logger.Infow("ignoring request body", "error", err)
+			return ""
+		}
+	}
+	return req.State
+}
+
 // extractIdFromHost extract XXXX from XXXX.127.0.0.1.nip.io
 func extractIdFromHost(hostIn string) string {
 	id := strings.Split(hostIn, ".")[0]
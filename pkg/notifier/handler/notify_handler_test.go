@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmax-cloud/alarm-operator/pkg/notification"
+	"go.uber.org/zap"
+)
+
+func TestRequestState(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        notification.State
+	}{
+		{"no body", "", "", ""},
+		{"text", "text/plain", "disk is full", ""},
+		{"json without content type", "", `{"state": "Resolved"}`, ""},
+		{"resolved", "application/json", `{"state": "Resolved"}`, notification.StateResolved},
+		{"resolved with charset", "application/json; charset=utf-8", `{"state": "Resolved"}`, notification.StateResolved},
+		{"firing", "application/json", `{"state": "Firing"}`, notification.StateFiring},
+		{"other json", "application/json", `{"message": "hi"}`, ""},
+		{"invalid json", "application/json", `not json`, ""},
+	}
+
+	h := &notificationHandler{logger: zap.NewNop().Sugar()}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		if got := h.requestState(r); got != tt.want {
+			t.Errorf("%s: requestState() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}